@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LabelClusterToken is set on the Secret copies managed by a ClusterToken,
+// with the UID of the ClusterToken as value.
+const LabelClusterToken = "token-renewer.barpilot.io/cluster-token"
+
+// ClusterTokenSpec defines the desired state of ClusterToken.
+type ClusterTokenSpec struct {
+	// Token is the spec of the Token renewing the credential. The Token is
+	// created in Namespace; its Secret is the source of the copies.
+	// +kubebuilder:validation:Required
+	Token TokenSpec `json:"token"`
+
+	// Namespace holding the managed Token.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// NamespaceSelector selects the namespaces receiving a copy of the Secret.
+	// +kubebuilder:validation:Required
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// TargetSecretName is the name of the Secret copies. Defaults to the name
+	// of the source Secret.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	TargetSecretName string `json:"targetSecretName,omitempty"`
+}
+
+// TargetName returns the name of the Secret copies.
+func (s ClusterTokenSpec) TargetName() string {
+	if s.TargetSecretName == "" {
+		return s.Token.SecretRef.Name
+	}
+	return s.TargetSecretName
+}
+
+// NamespaceSyncStatus is the sync state of the Secret copy in one namespace.
+type NamespaceSyncStatus struct {
+	// Namespace of the Secret copy.
+	Namespace string `json:"namespace"`
+	// Synced is true when the copy matches the source Secret.
+	Synced bool `json:"synced"`
+	// Message describes the last sync error.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastSyncTime is when the copy was last written.
+	// +optional
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// ClusterTokenStatus defines the observed state of ClusterToken.
+type ClusterTokenStatus struct {
+	// ExpirationTime is the expiration time of the managed Token.
+	// +optional
+	ExpirationTime metav1.Time `json:"expirationTime,omitempty"`
+
+	// Namespaces reports the sync state of each selected namespace.
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	Namespaces []NamespaceSyncStatus `json:"namespaces,omitempty"`
+
+	// ObservedGeneration is the generation last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the ClusterToken state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.spec.token.provider.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Expiration",type=string,format=date-time,JSONPath=`.status.expirationTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterToken is the Schema for the clustertokens API. It renews one token and
+// copies the renewed Secret into every namespace matching a label selector.
+type ClusterToken struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterTokenSpec   `json:"spec,omitempty"`
+	Status ClusterTokenStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterTokenList contains a list of ClusterToken.
+type ClusterTokenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterToken `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterToken{}, &ClusterTokenList{})
+}
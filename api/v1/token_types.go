@@ -0,0 +1,573 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TokenSpec defines the desired state of Token.
+// +kubebuilder:validation:XValidation:rule="has(self.metadata) || has(self.metadataFields)",message="one of metadata or metadataFields must be set"
+type TokenSpec struct {
+	// Provider is the plugin managing the token.
+	// +kubebuilder:validation:Required
+	Provider ProviderSpec `json:"provider"`
+	// Metadata is the opaque provider metadata, for example a Linode token ID.
+	// It is kept for compatibility; new providers should use MetadataFields.
+	// The spec metadata only seeds status.currentMetadata, which the controller
+	// updates after each renewal.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Metadata string `json:"metadata,omitempty"`
+	// MetadataFields is the structured provider metadata, for example an
+	// account ID, a region and a label.
+	// +kubebuilder:validation:MinProperties=1
+	// +optional
+	MetadataFields map[string]string `json:"metadataFields,omitempty"`
+	// Renewal defines when the token is renewed.
+	// +optional
+	Renewal RenewalSpec `json:"renewal,omitempty"`
+	// SecretRef is the Secret holding the token.
+	// +kubebuilder:validation:Required
+	SecretRef SecretReference `json:"secretRef"`
+	// BootstrapSecretRef is a Secret in the Token namespace holding the initial
+	// token. When the target Secret does not exist, the controller creates it,
+	// owned by the Token, with the token copied from this Secret.
+	// +optional
+	BootstrapSecretRef *BootstrapSecretReference `json:"bootstrapSecretRef,omitempty"`
+	// CredentialsSecretRef is a Secret in the Token namespace holding the
+	// credentials the provider authenticates with, for tokens that cannot
+	// renew themselves. Every entry of the Secret is sent to the provider.
+	// +optional
+	CredentialsSecretRef *CredentialsSecretReference `json:"credentialsSecretRef,omitempty"`
+	// Template renders additional entries into the target Secret.
+	// +optional
+	Template *SecretTemplateSpec `json:"template,omitempty"`
+	// RolloutTargets are the workloads, in the Token namespace, restarted
+	// when the token changes so that pods reading it from environment variables
+	// pick up the new value. They require the Secret to be in the Token
+	// namespace.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	RolloutTargets []RolloutTarget `json:"rolloutTargets,omitempty"`
+	// HistoryLimit is the number of renewals kept in status.history. Set it to
+	// 0 to disable the history.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+	// Suspend stops the controller from checking and renewing the token. The
+	// Secret and the provider are left untouched while the Token is suspended.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// DeletionPolicy tells whether the provider token is revoked when the
+	// Token is deleted.
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// DeletionPolicy tells what happens to the provider token when the Token is deleted.
+// +kubebuilder:validation:Enum=Retain;Revoke
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain leaves the provider token alive.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyRevoke revokes the provider token before the Token is
+	// removed, through the FinalizerRevoke finalizer.
+	DeletionPolicyRevoke DeletionPolicy = "Revoke"
+)
+
+// FinalizerRevoke holds the deletion of a Token until its provider token is revoked.
+const FinalizerRevoke = "token-renewer.barpilot.io/revoke"
+
+// DefaultHistoryLimit is the number of renewals kept when HistoryLimit is not set.
+const DefaultHistoryLimit = 10
+
+// DefaultRenewAtFraction is the renewal policy set when none is given. As a
+// fraction of the lifetime, it suits short-lived tokens as well as long ones.
+const DefaultRenewAtFraction = "0.66"
+
+// SetDefaults sets the defaults the defaulting webhook applies to a Token.
+func (s *TokenSpec) SetDefaults() {
+	if s.Renewal.BeforeDuration.Duration == 0 && s.Renewal.RenewAtFraction == "" {
+		s.Renewal.RenewAtFraction = DefaultRenewAtFraction
+	}
+	if s.SecretRef.Key == "" {
+		s.SecretRef.Key = DefaultSecretKey
+	}
+}
+
+// HistoryLength returns the number of renewals kept in the status history.
+func (s TokenSpec) HistoryLength() int {
+	if s.HistoryLimit == nil {
+		return DefaultHistoryLimit
+	}
+	return int(*s.HistoryLimit)
+}
+
+// SecretTemplateSpec describes additional Secret entries rendered from the token.
+type SecretTemplateSpec struct {
+	// Type of the target Secret, for example kubernetes.io/dockerconfigjson.
+	// The Secret is recreated when its current type differs.
+	// +optional
+	Type corev1.SecretType `json:"type,omitempty"`
+
+	// Data maps Secret keys to Go templates. Templates are rendered with
+	// .Token, .Metadata and .Expiration, and can use the b64enc, b64dec and
+	// toJson functions. The token keys take precedence over rendered entries.
+	// +kubebuilder:validation:MaxProperties=32
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[-._a-zA-Z0-9]+$'))",message="keys must consist of alphanumeric characters, '-', '_' or '.'"
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// DefaultSecretKey is the Secret key holding the token when SecretReference.Key is not set.
+const DefaultSecretKey = "token"
+
+// SecretReference selects the Secret holding the token and the keys it is stored under.
+// +kubebuilder:validation:XValidation:rule="!has(self.additionalKeys) || !has(self.key) || !(self.key in self.additionalKeys)",message="additionalKeys must not contain key"
+type SecretReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Secret. Defaults to the Token namespace. A Secret in
+	// another namespace must be allowed by a TokenSecretGrant in that namespace.
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the Secret entry holding the token. The token is read from and
+	// written to this key.
+	// +kubebuilder:default=token
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// AdditionalKeys are extra Secret keys that receive a copy of the renewed token.
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MinLength=1
+	// +kubebuilder:validation:items:MaxLength=253
+	// +kubebuilder:validation:items:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	AdditionalKeys []string `json:"additionalKeys,omitempty"`
+
+	// SetOwnerReference adds an owner reference to the Token on the Secret, so
+	// that the Secret is garbage collected with the Token. The Secret must be
+	// in the Token namespace. Removing the option leaves the owner reference.
+	// +optional
+	SetOwnerReference bool `json:"setOwnerReference,omitempty"`
+}
+
+// RolloutTarget selects workloads of the Token namespace restarted after a
+// token rotation, by name or by label selector. A TokenSecretGrant only lets
+// a Token write a Secret of another namespace, not restart its workloads, so
+// rollout targets are refused for a Secret outside the Token namespace.
+// +kubebuilder:validation:XValidation:rule="has(self.name) != has(self.selector)",message="exactly one of name or selector must be set"
+type RolloutTarget struct {
+	// Kind of the workloads.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	Kind string `json:"kind"`
+
+	// Name of the workload.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Selector matches the workloads by label.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// Kinds of workloads supported as rollout targets.
+const (
+	RolloutKindDeployment  = "Deployment"
+	RolloutKindStatefulSet = "StatefulSet"
+	RolloutKindDaemonSet   = "DaemonSet"
+)
+
+// CredentialsSecretReference selects the Secret holding the provider credentials.
+type CredentialsSecretReference struct {
+	// Name of the Secret in the Token namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+}
+
+// BootstrapSecretReference selects the Secret holding the initial token.
+type BootstrapSecretReference struct {
+	// Name of the Secret in the Token namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key of the Secret entry holding the initial token.
+	// +kubebuilder:default=token
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TokenKey returns the Secret key holding the initial token, falling back to DefaultSecretKey.
+func (s BootstrapSecretReference) TokenKey() string {
+	if s.Key == "" {
+		return DefaultSecretKey
+	}
+	return s.Key
+}
+
+// TokenKey returns the Secret key holding the token, falling back to DefaultSecretKey.
+func (s SecretReference) TokenKey() string {
+	if s.Key == "" {
+		return DefaultSecretKey
+	}
+	return s.Key
+}
+
+// SecretNamespace returns the namespace of the Secret holding the token.
+func (t *Token) SecretNamespace() string {
+	if t.Spec.SecretRef.Namespace == "" {
+		return t.Namespace
+	}
+	return t.Spec.SecretRef.Namespace
+}
+
+// ProviderSpec defines the desired state of the provider.
+type ProviderSpec struct {
+	// Name of the provider plugin.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// RenewalSpec defines the desired state of the renewal.
+// +kubebuilder:validation:XValidation:rule="!has(self.renewAtFraction) || !has(self.beforeDuration) || duration(self.beforeDuration) == duration('0s')",message="beforeDuration and renewAtFraction are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.minRemaining) || duration(self.minRemaining) == duration('0s') || has(self.renewAtFraction)",message="minRemaining requires renewAtFraction"
+type RenewalSpec struct {
+	// BeforeDuration renews the token this long before it expires. A margin
+	// longer than the token lifetime renews it at half of its lifetime instead.
+	// +optional
+	BeforeDuration metav1.Duration `json:"beforeDuration,omitempty"`
+	// RenewAtFraction renews the token once this fraction of its lifetime has
+	// elapsed, for example "0.66". The lifetime runs from status.issueTime to
+	// status.expirationTime.
+	// +kubebuilder:validation:Pattern=`^0?\.[0-9]+$`
+	// +kubebuilder:validation:XValidation:rule="double(self) > 0.0",message="renewAtFraction must be greater than 0"
+	// +optional
+	RenewAtFraction string `json:"renewAtFraction,omitempty"`
+	// MinRemaining renews the token at the latest when less than this duration
+	// of its lifetime remains, whatever the fraction.
+	// +optional
+	MinRemaining metav1.Duration `json:"minRemaining,omitempty"`
+	// Schedule restricts renewals to maintenance windows. A renewal due outside
+	// of a window is deferred to the next one, unless the token would expire first.
+	// +optional
+	Schedule *RenewalSchedule `json:"schedule,omitempty"`
+	// Overlap keeps the previous token valid this long after a renewal, so
+	// that consumers have time to reload the Secret before it is revoked.
+	// Providers without two-phase rotation revoke it during the renewal.
+	// +optional
+	Overlap metav1.Duration `json:"overlap,omitempty"`
+	// CheckInterval is how often the controller asks the provider for the
+	// validity of the token, to detect tokens revoked or shortened out of band.
+	// Defaults to the --validity-check-interval flag of the controller; 0s
+	// disables the checks.
+	// +optional
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+}
+
+// RenewalSchedule defines the maintenance windows during which renewals are allowed.
+type RenewalSchedule struct {
+	// TimeZone is the IANA time zone of the windows, for example "Europe/Paris".
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Windows during which renewals are allowed.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// MaintenanceWindow is a weekly recurring window.
+// +kubebuilder:validation:XValidation:rule="duration(self.duration) > duration('0s') && duration(self.duration) <= duration('168h')",message="duration must be between 0 and 168h"
+type MaintenanceWindow struct {
+	// Days of the week the window opens on. Empty means every day.
+	// +listType=set
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+	// Start is the opening time of the window, as HH:MM in the schedule time zone.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// Duration of the window.
+	Duration metav1.Duration `json:"duration"`
+}
+
+// Weekday is a day of the week.
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// Fraction returns RenewAtFraction as a number, or 0 when it is not set.
+func (r RenewalSpec) Fraction() (float64, error) {
+	if r.RenewAtFraction == "" {
+		return 0, nil
+	}
+
+	fraction, err := strconv.ParseFloat(r.RenewAtFraction, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid renewAtFraction %q: %w", r.RenewAtFraction, err)
+	}
+	if fraction <= 0 || fraction >= 1 {
+		return 0, fmt.Errorf("invalid renewAtFraction %q: must be between 0 and 1", r.RenewAtFraction)
+	}
+
+	return fraction, nil
+}
+
+// AnnotationRenewRequestedAt requests a one-shot renewal of the token. Any new
+// value, typically the current timestamp, triggers a renewal.
+const AnnotationRenewRequestedAt = "token-renewer.barpilot.io/renew-requested-at"
+
+// AnnotationTokenFingerprint is set on the pod template of the rollout targets
+// to the fingerprint of the token they were restarted for.
+const AnnotationTokenFingerprint = "token-renewer.barpilot.io/token-fingerprint"
+
+// Condition types reported in TokenStatus.Conditions.
+const (
+	// ConditionReady is True when the token is valid and stored in the target Secret.
+	ConditionReady = "Ready"
+	// ConditionRenewing is True while the token is inside its renewal window and
+	// has not been renewed yet.
+	ConditionRenewing = "Renewing"
+	// ConditionDegraded is True when the last reconciliation failed.
+	ConditionDegraded = "Degraded"
+	// ConditionExpired is True when the known expiration time is in the past.
+	ConditionExpired = "Expired"
+	// ConditionSuspended is True when spec.suspend pauses the reconciliation.
+	ConditionSuspended = "Suspended"
+	// ConditionValid is False when the provider no longer validates the token,
+	// for example after it was revoked out of band.
+	ConditionValid = "Valid"
+)
+
+// CurrentMetadata is the provider metadata of the token currently stored in the Secret.
+type CurrentMetadata struct {
+	// Metadata is the opaque provider metadata.
+	// +optional
+	Metadata string `json:"metadata,omitempty"`
+	// MetadataFields is the structured provider metadata.
+	// +optional
+	MetadataFields map[string]string `json:"metadataFields,omitempty"`
+	// SeedHash is a hash of the spec metadata this metadata was seeded from.
+	// The status is re-seeded when the spec metadata changes.
+	// +optional
+	SeedHash string `json:"seedHash,omitempty"`
+}
+
+// RenewalOutcome is the result of a renewal attempt.
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type RenewalOutcome string
+
+// Renewal outcomes.
+const (
+	RenewalSucceeded RenewalOutcome = "Succeeded"
+	RenewalFailed    RenewalOutcome = "Failed"
+)
+
+// ProviderMetadata is the provider identity of a token.
+type ProviderMetadata struct {
+	// Metadata is the opaque provider metadata.
+	// +optional
+	Metadata string `json:"metadata,omitempty"`
+	// MetadataFields is the structured provider metadata.
+	// +optional
+	MetadataFields map[string]string `json:"metadataFields,omitempty"`
+}
+
+// RenewalRecord describes one renewal attempt.
+type RenewalRecord struct {
+	// Time of the renewal attempt.
+	Time metav1.Time `json:"time"`
+	// Outcome of the renewal attempt.
+	Outcome RenewalOutcome `json:"outcome"`
+	// PreviousMetadata identifies the renewed token on the provider side.
+	// +optional
+	PreviousMetadata *ProviderMetadata `json:"previousMetadata,omitempty"`
+	// Metadata identifies the new token on the provider side.
+	// +optional
+	Metadata *ProviderMetadata `json:"metadata,omitempty"`
+	// ExpirationTime is when the new token expires.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+	// Fingerprint is a truncated SHA-256 of the new token value.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Message describes why the renewal failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// WorkloadRollout is the rollout state of a workload matched by a rollout target.
+type WorkloadRollout struct {
+	// Kind of the workload.
+	Kind string `json:"kind"`
+	// Name of the workload.
+	Name string `json:"name"`
+	// Fingerprint of the token the workload was last restarted for.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Time is when the workload was last restarted.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+	// Error is the error of the last restart attempt, if it failed.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PendingRevocation is a previous token kept valid by a two-phase rotation.
+type PendingRevocation struct {
+	// Metadata identifies the previous token on the provider side.
+	Metadata ProviderMetadata `json:"metadata"`
+	// Fingerprint is a truncated SHA-256 of the previous token value.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// RevokeAfter is when the overlap ends and the previous token is revoked.
+	RevokeAfter metav1.Time `json:"revokeAfter"`
+}
+
+// TokenStatus defines the observed state of Token.
+type TokenStatus struct {
+	// ExpirationTime is when the current token expires.
+	// +optional
+	ExpirationTime metav1.Time `json:"expirationTime,omitempty"`
+
+	// IssueTime is when the current token was issued, or first observed by the
+	// controller when it was created outside of it.
+	// +optional
+	IssueTime metav1.Time `json:"issueTime,omitempty"`
+
+	// CurrentMetadata identifies the current token on the provider side. It is
+	// seeded from the spec metadata and updated after every renewal.
+	// +optional
+	CurrentMetadata *CurrentMetadata `json:"currentMetadata,omitempty"`
+
+	// TokenFingerprint is a truncated SHA-256 of the token value the expiration
+	// time was read for. A change means the Secret was modified out of band.
+	// +optional
+	TokenFingerprint string `json:"tokenFingerprint,omitempty"`
+
+	// LastValidityCheckTime is when the provider last reported the expiration
+	// time of the token.
+	// +optional
+	LastValidityCheckTime *metav1.Time `json:"lastValidityCheckTime,omitempty"`
+
+	// LastRenewalTime is when the token was last renewed successfully.
+	// +optional
+	LastRenewalTime *metav1.Time `json:"lastRenewalTime,omitempty"`
+
+	// History lists the latest renewal attempts, newest first, bounded by
+	// spec.historyLimit.
+	// +optional
+	History []RenewalRecord `json:"history,omitempty"`
+
+	// PendingRevocations lists the previous tokens still valid after a
+	// two-phase rotation, waiting for the end of their overlap.
+	// +optional
+	PendingRevocations []PendingRevocation `json:"pendingRevocations,omitempty"`
+
+	// RotationGeneration counts the renewals committed by the controller.
+	// +optional
+	RotationGeneration int64 `json:"rotationGeneration,omitempty"`
+
+	// RenewalIdempotencyKey is the idempotency key of the renewal in progress,
+	// derived from the Token UID and the next rotation generation. It is sent
+	// with every retry of the renewal so that the provider mints one token.
+	// +optional
+	RenewalIdempotencyKey string `json:"renewalIdempotencyKey,omitempty"`
+
+	// Rollouts reports the workloads matched by spec.rolloutTargets and the
+	// token they were last restarted for, or first matched with.
+	// +listType=map
+	// +listMapKey=kind
+	// +listMapKey=name
+	// +optional
+	Rollouts []WorkloadRollout `json:"rollouts,omitempty"`
+
+	// LastHandledRenewRequest is the last value of the renew-requested-at
+	// annotation the controller acted upon.
+	// +optional
+	LastHandledRenewRequest string `json:"lastHandledRenewRequest,omitempty"`
+
+	// ObservedGeneration is the generation last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the Token state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.spec.provider.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Expiration",type=string,format=date-time,JSONPath=`.status.expirationTime`
+// +kubebuilder:printcolumn:name="Last Renewal",type=date,JSONPath=`.status.lastRenewalTime`
+// +kubebuilder:printcolumn:name="Suspended",type=boolean,JSONPath=`.spec.suspend`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Token is the Schema for the tokens API.
+type Token struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TokenSpec   `json:"spec,omitempty"`
+	Status TokenStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TokenList contains a list of Token.
+type TokenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Token `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Token{}, &TokenList{})
+}
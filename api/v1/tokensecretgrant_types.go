@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TokenSecretGrantSpec defines which Tokens may reference Secrets in the
+// namespace of the grant.
+type TokenSecretGrantSpec struct {
+	// From lists the Tokens allowed to reference Secrets in this namespace.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	From []TokenSecretGrantFrom `json:"from"`
+
+	// To lists the Secrets that may be referenced. All the Secrets of the
+	// namespace may be referenced when empty.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	To []TokenSecretGrantTo `json:"to,omitempty"`
+}
+
+// TokenSecretGrantFrom selects the Tokens of a namespace.
+type TokenSecretGrantFrom struct {
+	// Namespace of the Tokens.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace"`
+
+	// Name of the Token. Every Token of the namespace is selected when empty.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// TokenSecretGrantTo selects a Secret of the grant namespace.
+type TokenSecretGrantTo struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+}
+
+// Allows reports whether the grant lets the Token reference its Secret. The
+// grant must live in the namespace of the Secret.
+func (g *TokenSecretGrant) Allows(token *Token) bool {
+	if g.Namespace != token.SecretNamespace() {
+		return false
+	}
+
+	from := false
+	for _, f := range g.Spec.From {
+		if f.Namespace == token.Namespace && (f.Name == "" || f.Name == token.Name) {
+			from = true
+			break
+		}
+	}
+	if !from {
+		return false
+	}
+
+	if len(g.Spec.To) == 0 {
+		return true
+	}
+	for _, to := range g.Spec.To {
+		if to.Name == token.Spec.SecretRef.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretReferenceGranted reports whether the Token may use its Secret: either
+// the Secret is in the Token namespace or one of the grants allows it.
+func SecretReferenceGranted(token *Token, grants []TokenSecretGrant) bool {
+	if token.SecretNamespace() == token.Namespace {
+		return true
+	}
+	for i := range grants {
+		if grants[i].Allows(token) {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TokenSecretGrant is the Schema for the tokensecretgrants API. Created in the
+// namespace of a Secret, it allows Tokens of other namespaces to read and
+// write that Secret, like a Gateway API ReferenceGrant.
+type TokenSecretGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TokenSecretGrantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TokenSecretGrantList contains a list of TokenSecretGrant.
+type TokenSecretGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TokenSecretGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TokenSecretGrant{}, &TokenSecretGrantList{})
+}
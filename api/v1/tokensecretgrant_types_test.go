@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestSecretReferenceGranted tests the matching of Tokens against grants
+func TestSecretReferenceGranted(t *testing.T) {
+	newGrant := func(namespace string, from []TokenSecretGrantFrom, to []TokenSecretGrantTo) TokenSecretGrant {
+		return TokenSecretGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "grant", Namespace: namespace},
+			Spec:       TokenSecretGrantSpec{From: from, To: to},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		secretRef SecretReference
+		grants    []TokenSecretGrant
+		want      bool
+	}{
+		{
+			name:      "same_namespace",
+			secretRef: SecretReference{Name: "secret"},
+			want:      true,
+		},
+		{
+			name:      "same_namespace_explicit",
+			secretRef: SecretReference{Name: "secret", Namespace: "tokens"},
+			want:      true,
+		},
+		{
+			name:      "no_grant",
+			secretRef: SecretReference{Name: "secret", Namespace: "apps"},
+			want:      false,
+		},
+		{
+			name:      "namespace_grant",
+			secretRef: SecretReference{Name: "secret", Namespace: "apps"},
+			grants:    []TokenSecretGrant{newGrant("apps", []TokenSecretGrantFrom{{Namespace: "tokens"}}, nil)},
+			want:      true,
+		},
+		{
+			name:      "grant_in_other_namespace",
+			secretRef: SecretReference{Name: "secret", Namespace: "apps"},
+			grants:    []TokenSecretGrant{newGrant("other", []TokenSecretGrantFrom{{Namespace: "tokens"}}, nil)},
+			want:      false,
+		},
+		{
+			name:      "grant_for_other_token",
+			secretRef: SecretReference{Name: "secret", Namespace: "apps"},
+			grants:    []TokenSecretGrant{newGrant("apps", []TokenSecretGrantFrom{{Namespace: "tokens", Name: "other"}}, nil)},
+			want:      false,
+		},
+		{
+			name:      "grant_for_token",
+			secretRef: SecretReference{Name: "secret", Namespace: "apps"},
+			grants:    []TokenSecretGrant{newGrant("apps", []TokenSecretGrantFrom{{Namespace: "tokens", Name: "token"}}, nil)},
+			want:      true,
+		},
+		{
+			name:      "grant_for_other_secret",
+			secretRef: SecretReference{Name: "secret", Namespace: "apps"},
+			grants: []TokenSecretGrant{newGrant("apps", []TokenSecretGrantFrom{{Namespace: "tokens"}},
+				[]TokenSecretGrantTo{{Name: "other"}})},
+			want: false,
+		},
+		{
+			name:      "grant_for_secret",
+			secretRef: SecretReference{Name: "secret", Namespace: "apps"},
+			grants: []TokenSecretGrant{newGrant("apps", []TokenSecretGrantFrom{{Namespace: "tokens"}},
+				[]TokenSecretGrantTo{{Name: "other"}, {Name: "secret"}})},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &Token{
+				ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "tokens"},
+				Spec:       TokenSpec{SecretRef: tt.secretRef},
+			}
+			if got := SecretReferenceGranted(token, tt.grants); got != tt.want {
+				t.Errorf("SecretReferenceGranted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+// AnnotationConversionData holds the v1 spec fields a v1beta1 Token cannot
+// represent, so that a round trip through v1beta1 does not lose them. The
+// status is converted field by field: a status update cannot change the
+// annotations, and the history would grow the annotation with every renewal.
+const AnnotationConversionData = "token-renewer.barpilot.io/conversion-data"
+
+// conversionData is the content of AnnotationConversionData.
+type conversionData struct {
+	Spec tokenrenewerv1.TokenSpec `json:"spec"`
+}
+
+var _ conversion.Convertible = &Token{}
+
+// ConvertTo converts this Token (v1beta1) to the Hub version (v1).
+func (src *Token) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*tokenrenewerv1.Token)
+	if !ok {
+		return fmt.Errorf("expected a v1 Token but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	// Restore the fields v1beta1 cannot represent, then apply the v1beta1 ones
+	if raw, ok := dst.Annotations[AnnotationConversionData]; ok {
+		var data conversionData
+		if err := json.Unmarshal([]byte(raw), &data); err != nil {
+			return fmt.Errorf("unable to restore conversion data: %w", err)
+		}
+		dst.Spec = data.Spec
+
+		delete(dst.Annotations, AnnotationConversionData)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	src.convertTo(dst)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version (v1beta1).
+func (dst *Token) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*tokenrenewerv1.Token)
+	if !ok {
+		return fmt.Errorf("expected a v1 Token but got %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	spec := &dst.Spec
+	spec.Provider = ProviderSpec{Name: src.Spec.Provider.Name}
+	spec.Metadata = src.Spec.Metadata
+	spec.MetadataFields = maps.Clone(src.Spec.MetadataFields)
+	spec.Renewval = RenewvalSpec{
+		BeforeDuration:  src.Spec.Renewal.BeforeDuration,
+		RenewAtFraction: src.Spec.Renewal.RenewAtFraction,
+		MinRemaining:    src.Spec.Renewal.MinRemaining,
+	}
+	if schedule := src.Spec.Renewal.Schedule; schedule != nil {
+		spec.Renewval.Schedule = &RenewalSchedule{TimeZone: schedule.TimeZone}
+		for _, window := range schedule.Windows {
+			w := MaintenanceWindow{Start: window.Start, Duration: window.Duration}
+			for _, day := range window.Days {
+				w.Days = append(w.Days, Weekday(day))
+			}
+			spec.Renewval.Schedule.Windows = append(spec.Renewval.Schedule.Windows, w)
+		}
+	}
+	spec.SecretRef = SecretReference{
+		Name:           src.Spec.SecretRef.Name,
+		Key:            src.Spec.SecretRef.Key,
+		AdditionalKeys: append([]string(nil), src.Spec.SecretRef.AdditionalKeys...),
+	}
+	spec.Template = nil
+	if template := src.Spec.Template; template != nil {
+		spec.Template = &SecretTemplateSpec{Type: template.Type, Data: maps.Clone(template.Data)}
+	}
+
+	status := &dst.Status
+	status.ExpirationTime = src.Status.ExpirationTime
+	status.IssueTime = src.Status.IssueTime
+	status.CurrentMetadata = nil
+	if current := src.Status.CurrentMetadata; current != nil {
+		status.CurrentMetadata = &CurrentMetadata{
+			Metadata:       current.Metadata,
+			MetadataFields: maps.Clone(current.MetadataFields),
+			SeedHash:       current.SeedHash,
+		}
+	}
+	status.TokenFingerprint = src.Status.TokenFingerprint
+	status.LastValidityCheckTime = src.Status.LastValidityCheckTime.DeepCopy()
+	status.LastRenewalTime = src.Status.LastRenewalTime.DeepCopy()
+	status.History = nil
+	for _, record := range src.Status.History {
+		status.History = append(status.History, RenewalRecord{
+			Time:             record.Time,
+			Outcome:          RenewalOutcome(record.Outcome),
+			PreviousMetadata: providerMetadataFrom(record.PreviousMetadata),
+			Metadata:         providerMetadataFrom(record.Metadata),
+			ExpirationTime:   record.ExpirationTime.DeepCopy(),
+			Fingerprint:      record.Fingerprint,
+			Message:          record.Message,
+		})
+	}
+	status.PendingRevocations = nil
+	for _, pending := range src.Status.PendingRevocations {
+		status.PendingRevocations = append(status.PendingRevocations, PendingRevocation{
+			Metadata:    *providerMetadataFrom(&pending.Metadata),
+			Fingerprint: pending.Fingerprint,
+			RevokeAfter: pending.RevokeAfter,
+		})
+	}
+	status.RotationGeneration = src.Status.RotationGeneration
+	status.RenewalIdempotencyKey = src.Status.RenewalIdempotencyKey
+	status.Rollouts = nil
+	for _, rollout := range src.Status.Rollouts {
+		status.Rollouts = append(status.Rollouts, WorkloadRollout{
+			Kind:        rollout.Kind,
+			Name:        rollout.Name,
+			Fingerprint: rollout.Fingerprint,
+			Time:        rollout.Time.DeepCopy(),
+			Error:       rollout.Error,
+		})
+	}
+	status.LastHandledRenewRequest = src.Status.LastHandledRenewRequest
+	status.ObservedGeneration = src.Status.ObservedGeneration
+	status.Conditions = append(status.Conditions[:0:0], src.Status.Conditions...)
+
+	// Keep the v1 spec fields lost by the conversion in an annotation
+	restored := &tokenrenewerv1.Token{}
+	dst.convertTo(restored)
+	if !equality.Semantic.DeepEqual(restored.Spec, src.Spec) {
+		raw, err := json.Marshal(conversionData{Spec: src.Spec})
+		if err != nil {
+			return fmt.Errorf("unable to store conversion data: %w", err)
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[AnnotationConversionData] = string(raw)
+	}
+
+	return nil
+}
+
+// convertTo sets the v1 fields represented in v1beta1, leaving the others untouched.
+func (src *Token) convertTo(dst *tokenrenewerv1.Token) {
+	spec := &dst.Spec
+	spec.Provider.Name = src.Spec.Provider.Name
+	spec.Metadata = src.Spec.Metadata
+	spec.MetadataFields = maps.Clone(src.Spec.MetadataFields)
+	spec.Renewal.BeforeDuration = src.Spec.Renewval.BeforeDuration
+	spec.Renewal.RenewAtFraction = src.Spec.Renewval.RenewAtFraction
+	spec.Renewal.MinRemaining = src.Spec.Renewval.MinRemaining
+	spec.Renewal.Schedule = nil
+	if schedule := src.Spec.Renewval.Schedule; schedule != nil {
+		spec.Renewal.Schedule = &tokenrenewerv1.RenewalSchedule{TimeZone: schedule.TimeZone}
+		for _, window := range schedule.Windows {
+			w := tokenrenewerv1.MaintenanceWindow{Start: window.Start, Duration: window.Duration}
+			for _, day := range window.Days {
+				w.Days = append(w.Days, tokenrenewerv1.Weekday(day))
+			}
+			spec.Renewal.Schedule.Windows = append(spec.Renewal.Schedule.Windows, w)
+		}
+	}
+	spec.SecretRef.Name = src.Spec.SecretRef.Name
+	spec.SecretRef.Key = src.Spec.SecretRef.Key
+	spec.SecretRef.AdditionalKeys = append([]string(nil), src.Spec.SecretRef.AdditionalKeys...)
+	if template := src.Spec.Template; template != nil {
+		if spec.Template == nil {
+			spec.Template = &tokenrenewerv1.SecretTemplateSpec{}
+		}
+		spec.Template.Type = template.Type
+		spec.Template.Data = maps.Clone(template.Data)
+	} else {
+		spec.Template = nil
+	}
+
+	status := &dst.Status
+	status.ExpirationTime = src.Status.ExpirationTime
+	status.IssueTime = src.Status.IssueTime
+	if current := src.Status.CurrentMetadata; current != nil {
+		if status.CurrentMetadata == nil {
+			status.CurrentMetadata = &tokenrenewerv1.CurrentMetadata{}
+		}
+		status.CurrentMetadata.Metadata = current.Metadata
+		status.CurrentMetadata.MetadataFields = maps.Clone(current.MetadataFields)
+		status.CurrentMetadata.SeedHash = current.SeedHash
+	} else {
+		status.CurrentMetadata = nil
+	}
+	status.TokenFingerprint = src.Status.TokenFingerprint
+	status.LastValidityCheckTime = src.Status.LastValidityCheckTime.DeepCopy()
+	status.LastRenewalTime = src.Status.LastRenewalTime.DeepCopy()
+	status.History = nil
+	for _, record := range src.Status.History {
+		status.History = append(status.History, tokenrenewerv1.RenewalRecord{
+			Time:             record.Time,
+			Outcome:          tokenrenewerv1.RenewalOutcome(record.Outcome),
+			PreviousMetadata: record.PreviousMetadata.convertTo(),
+			Metadata:         record.Metadata.convertTo(),
+			ExpirationTime:   record.ExpirationTime.DeepCopy(),
+			Fingerprint:      record.Fingerprint,
+			Message:          record.Message,
+		})
+	}
+	status.PendingRevocations = nil
+	for _, pending := range src.Status.PendingRevocations {
+		status.PendingRevocations = append(status.PendingRevocations, tokenrenewerv1.PendingRevocation{
+			Metadata:    *pending.Metadata.convertTo(),
+			Fingerprint: pending.Fingerprint,
+			RevokeAfter: pending.RevokeAfter,
+		})
+	}
+	status.RotationGeneration = src.Status.RotationGeneration
+	status.RenewalIdempotencyKey = src.Status.RenewalIdempotencyKey
+	status.Rollouts = nil
+	for _, rollout := range src.Status.Rollouts {
+		status.Rollouts = append(status.Rollouts, tokenrenewerv1.WorkloadRollout{
+			Kind:        rollout.Kind,
+			Name:        rollout.Name,
+			Fingerprint: rollout.Fingerprint,
+			Time:        rollout.Time.DeepCopy(),
+			Error:       rollout.Error,
+		})
+	}
+	status.LastHandledRenewRequest = src.Status.LastHandledRenewRequest
+	status.ObservedGeneration = src.Status.ObservedGeneration
+	status.Conditions = append(status.Conditions[:0:0], src.Status.Conditions...)
+}
+
+// providerMetadataFrom converts v1 provider metadata to v1beta1.
+func providerMetadataFrom(src *tokenrenewerv1.ProviderMetadata) *ProviderMetadata {
+	if src == nil {
+		return nil
+	}
+	return &ProviderMetadata{Metadata: src.Metadata, MetadataFields: maps.Clone(src.MetadataFields)}
+}
+
+// convertTo converts the provider metadata to v1.
+func (src *ProviderMetadata) convertTo() *tokenrenewerv1.ProviderMetadata {
+	if src == nil {
+		return nil
+	}
+	return &tokenrenewerv1.ProviderMetadata{Metadata: src.Metadata, MetadataFields: maps.Clone(src.MetadataFields)}
+}
@@ -0,0 +1,221 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+func newConversionToken() *Token {
+	return &Token{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-token",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnotationRenewRequestedAt: "2025-01-01T00:00:00Z"},
+		},
+		Spec: TokenSpec{
+			Provider:       ProviderSpec{Name: "linode"},
+			Metadata:       "12345",
+			MetadataFields: map[string]string{"id": "12345"},
+			Renewval: RenewvalSpec{
+				RenewAtFraction: "0.66",
+				MinRemaining:    metav1.Duration{Duration: time.Hour},
+				Schedule: &RenewalSchedule{
+					TimeZone: "Europe/Paris",
+					Windows: []MaintenanceWindow{{
+						Days:     []Weekday{"Monday"},
+						Start:    "22:00",
+						Duration: metav1.Duration{Duration: 4 * time.Hour},
+					}},
+				},
+			},
+			SecretRef: SecretReference{Name: "my-secret", Key: "api-key", AdditionalKeys: []string{"password"}},
+			Template:  &SecretTemplateSpec{Type: "Opaque", Data: map[string]string{"env": "TOKEN={{ .Token }}"}},
+		},
+		Status: TokenStatus{
+			ExpirationTime:          metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+			IssueTime:               metav1.NewTime(time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)),
+			CurrentMetadata:         &CurrentMetadata{Metadata: "67890", SeedHash: "abc"},
+			LastHandledRenewRequest: "2025-01-01T00:00:00Z",
+			ObservedGeneration:      2,
+			Conditions: []metav1.Condition{{
+				Type:   ConditionReady,
+				Status: metav1.ConditionTrue,
+				Reason: "TokenValid",
+			}},
+		},
+	}
+}
+
+// TestTokenConversionRoundTrip tests that v1beta1 -> v1 -> v1beta1 is lossless
+func TestTokenConversionRoundTrip(t *testing.T) {
+	src := newConversionToken()
+
+	hub := &tokenrenewerv1.Token{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if hub.Spec.Renewal.RenewAtFraction != "0.66" {
+		t.Errorf("renewval not converted to renewal: %+v", hub.Spec.Renewal)
+	}
+
+	dst := &Token{}
+	if err := dst.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if _, ok := dst.Annotations[AnnotationConversionData]; ok {
+		t.Error("conversion data stored although v1beta1 represents every field")
+	}
+	if !equality.Semantic.DeepEqual(src, dst) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", dst, src)
+	}
+}
+
+// TestTokenConversionHubRoundTrip tests that v1 -> v1beta1 -> v1 is lossless
+func TestTokenConversionHubRoundTrip(t *testing.T) {
+	hub := &tokenrenewerv1.Token{}
+	if err := newConversionToken().ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+
+	spoke := &Token{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	restored := &tokenrenewerv1.Token{}
+	if err := spoke.ConvertTo(restored); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(hub, restored) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", restored, hub)
+	}
+}
+
+// TestTokenConversionData tests that stored v1 fields are restored and that
+// the v1beta1 fields take precedence over them
+func TestTokenConversionData(t *testing.T) {
+	stored := tokenrenewerv1.Token{}
+	stored.Spec.Metadata = "stale"
+	raw, err := json.Marshal(conversionData{Spec: stored.Spec})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := newConversionToken()
+	src.Annotations[AnnotationConversionData] = string(raw)
+	src.Status.LastHandledRenewRequest = ""
+
+	hub := &tokenrenewerv1.Token{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hub.Annotations[AnnotationConversionData]; ok {
+		t.Error("conversion data annotation leaked into v1")
+	}
+	if _, ok := src.Annotations[AnnotationConversionData]; !ok {
+		t.Error("source annotations modified")
+	}
+	if hub.Spec.Metadata != "12345" {
+		t.Errorf("metadata = %q, want the v1beta1 value", hub.Spec.Metadata)
+	}
+	if hub.Status.LastHandledRenewRequest != "" {
+		t.Errorf("lastHandledRenewRequest = %q, want the v1beta1 value", hub.Status.LastHandledRenewRequest)
+	}
+}
+
+// TestTokenConversionSecretNamespace tests that a v1 secretRef.namespace
+// survives a round trip through v1beta1
+func TestTokenConversionSecretNamespace(t *testing.T) {
+	hub := &tokenrenewerv1.Token{}
+	if err := newConversionToken().ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	hub.Spec.SecretRef.Namespace = "apps"
+
+	spoke := &Token{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := spoke.Annotations[AnnotationConversionData]; !ok {
+		t.Fatal("conversion data not stored for secretRef.namespace")
+	}
+
+	restored := &tokenrenewerv1.Token{}
+	if err := spoke.ConvertTo(restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Spec.SecretRef.Namespace != "apps" {
+		t.Errorf("secretRef.namespace = %q, want %q", restored.Spec.SecretRef.Namespace, "apps")
+	}
+}
+
+// TestTokenConversionStatus tests that the v1 status, which the controller
+// relies on, survives a round trip through v1beta1 without being stored in the
+// conversion data annotation
+func TestTokenConversionStatus(t *testing.T) {
+	hub := &tokenrenewerv1.Token{}
+	if err := newConversionToken().ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	checked := metav1.NewTime(time.Date(2029, 6, 1, 0, 0, 0, 0, time.UTC))
+	renewed := metav1.NewTime(time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC))
+	expiration := metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	hub.Status.TokenFingerprint = "0123456789abcdef"
+	hub.Status.LastValidityCheckTime = &checked
+	hub.Status.LastRenewalTime = &renewed
+	hub.Status.History = []tokenrenewerv1.RenewalRecord{{
+		Time:             renewed,
+		Outcome:          tokenrenewerv1.RenewalSucceeded,
+		PreviousMetadata: &tokenrenewerv1.ProviderMetadata{Metadata: "12345"},
+		Metadata:         &tokenrenewerv1.ProviderMetadata{Metadata: "67890", MetadataFields: map[string]string{"id": "67890"}},
+		ExpirationTime:   &expiration,
+		Fingerprint:      "0123456789abcdef",
+	}, {
+		Time:    metav1.NewTime(renewed.Add(-time.Hour)),
+		Outcome: tokenrenewerv1.RenewalFailed,
+		Message: "unable to renew token",
+	}}
+	hub.Status.PendingRevocations = []tokenrenewerv1.PendingRevocation{{
+		Metadata:    tokenrenewerv1.ProviderMetadata{Metadata: "12345"},
+		Fingerprint: "fedcba9876543210",
+		RevokeAfter: metav1.NewTime(renewed.Add(time.Hour)),
+	}}
+	hub.Status.RotationGeneration = 3
+	hub.Status.RenewalIdempotencyKey = "token-uid/4"
+	hub.Status.Rollouts = []tokenrenewerv1.WorkloadRollout{{
+		Kind:        "Deployment",
+		Name:        "web",
+		Fingerprint: "0123456789abcdef",
+		Time:        &renewed,
+	}}
+
+	spoke := &Token{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := spoke.Annotations[AnnotationConversionData]; ok {
+		t.Errorf("conversion data stored for status fields: %s", raw)
+	}
+
+	restored := &tokenrenewerv1.Token{}
+	if err := spoke.ConvertTo(restored); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(hub.Status, restored.Status) {
+		t.Errorf("status round trip mismatch:\n got %+v\nwant %+v", restored.Status, hub.Status)
+	}
+
+	hub.Spec.SecretRef.Namespace = "apps"
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if raw := spoke.Annotations[AnnotationConversionData]; strings.Contains(raw, "history") || strings.Contains(raw, "status") {
+		t.Errorf("conversion data holds the status: %s", raw)
+	}
+}
@@ -17,21 +17,91 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"strconv"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // TokenSpec defines the desired state of Token.
+// +kubebuilder:validation:XValidation:rule="has(self.metadata) || has(self.metadataFields)",message="one of metadata or metadataFields must be set"
 type TokenSpec struct {
 	// +kubebuilder:validation:Required
 	Provider ProviderSpec `json:"provider"`
-	// +kubebuilder:validation:Required
+	// Metadata is the opaque provider metadata, for example a Linode token ID.
+	// It is kept for compatibility; new providers should use MetadataFields.
+	// The spec metadata only seeds status.currentMetadata, which the controller
+	// updates after each renewal.
 	// +kubebuilder:validation:MinLength=1
-	Metadata string `json:"metadata"`
+	// +optional
+	Metadata string `json:"metadata,omitempty"`
+	// MetadataFields is the structured provider metadata, for example an
+	// account ID, a region and a label.
+	// +kubebuilder:validation:MinProperties=1
+	// +optional
+	MetadataFields map[string]string `json:"metadataFields,omitempty"`
 	// +kubebuilder:validation:Required
 	Renewval RenewvalSpec `json:"renewval,omitempty"`
 	// +kubebuilder:validation:Required
-	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+	SecretRef SecretReference `json:"secretRef"`
+	// Template renders additional entries into the target Secret.
+	// +optional
+	Template *SecretTemplateSpec `json:"template,omitempty"`
+}
+
+// SecretTemplateSpec describes additional Secret entries rendered from the token.
+type SecretTemplateSpec struct {
+	// Type of the target Secret, for example kubernetes.io/dockerconfigjson.
+	// The Secret is recreated when its current type differs.
+	// +optional
+	Type corev1.SecretType `json:"type,omitempty"`
+
+	// Data maps Secret keys to Go templates. Templates are rendered with
+	// .Token, .Metadata and .Expiration, and can use the b64enc, b64dec and
+	// toJson functions. The token keys take precedence over rendered entries.
+	// +kubebuilder:validation:MaxProperties=32
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[-._a-zA-Z0-9]+$'))",message="keys must consist of alphanumeric characters, '-', '_' or '.'"
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// DefaultSecretKey is the Secret key holding the token when SecretReference.Key is not set.
+const DefaultSecretKey = "token"
+
+// SecretReference selects the Secret holding the token and the keys it is stored under.
+// +kubebuilder:validation:XValidation:rule="!has(self.additionalKeys) || !has(self.key) || !(self.key in self.additionalKeys)",message="additionalKeys must not contain key"
+type SecretReference struct {
+	// Name of the Secret in the Token namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key of the Secret entry holding the token. The token is read from and
+	// written to this key.
+	// +kubebuilder:default=token
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// AdditionalKeys are extra Secret keys that receive a copy of the renewed token.
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MinLength=1
+	// +kubebuilder:validation:items:MaxLength=253
+	// +kubebuilder:validation:items:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	AdditionalKeys []string `json:"additionalKeys,omitempty"`
+}
+
+// TokenKey returns the Secret key holding the token, falling back to DefaultSecretKey.
+func (s SecretReference) TokenKey() string {
+	if s.Key == "" {
+		return DefaultSecretKey
+	}
+	return s.Key
 }
 
 // ProviderSpec defines the desired state of the provider.
@@ -41,17 +111,252 @@ type ProviderSpec struct {
 }
 
 // RenewvalSpec defines the desired state of the renewval.
+// +kubebuilder:validation:XValidation:rule="!has(self.renewAtFraction) || !has(self.beforeDuration) || duration(self.beforeDuration) == duration('0s')",message="beforeDuration and renewAtFraction are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.minRemaining) || duration(self.minRemaining) == duration('0s') || has(self.renewAtFraction)",message="minRemaining requires renewAtFraction"
 type RenewvalSpec struct {
+	// BeforeDuration renews the token this long before it expires. A margin
+	// longer than the token lifetime renews it at half of its lifetime instead.
+	// +optional
 	BeforeDuration metav1.Duration `json:"beforeDuration,omitempty"`
+	// RenewAtFraction renews the token once this fraction of its lifetime has
+	// elapsed, for example "0.66". The lifetime runs from status.issueTime to
+	// status.expirationTime.
+	// +kubebuilder:validation:Pattern=`^0?\.[0-9]+$`
+	// +kubebuilder:validation:XValidation:rule="double(self) > 0.0",message="renewAtFraction must be greater than 0"
+	// +optional
+	RenewAtFraction string `json:"renewAtFraction,omitempty"`
+	// MinRemaining renews the token at the latest when less than this duration
+	// of its lifetime remains, whatever the fraction.
+	// +optional
+	MinRemaining metav1.Duration `json:"minRemaining,omitempty"`
+	// Schedule restricts renewals to maintenance windows. A renewal due outside
+	// of a window is deferred to the next one, unless the token would expire first.
+	// +optional
+	Schedule *RenewalSchedule `json:"schedule,omitempty"`
+}
+
+// RenewalSchedule defines the maintenance windows during which renewals are allowed.
+type RenewalSchedule struct {
+	// TimeZone is the IANA time zone of the windows, for example "Europe/Paris".
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Windows during which renewals are allowed.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	Windows []MaintenanceWindow `json:"windows"`
 }
 
-// TokenStatus defines the observed state of Token.
+// MaintenanceWindow is a weekly recurring window.
+// +kubebuilder:validation:XValidation:rule="duration(self.duration) > duration('0s') && duration(self.duration) <= duration('168h')",message="duration must be between 0 and 168h"
+type MaintenanceWindow struct {
+	// Days of the week the window opens on. Empty means every day.
+	// +listType=set
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+	// Start is the opening time of the window, as HH:MM in the schedule time zone.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// Duration of the window.
+	Duration metav1.Duration `json:"duration"`
+}
+
+// Weekday is a day of the week.
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// Fraction returns RenewAtFraction as a number, or 0 when it is not set.
+func (r RenewvalSpec) Fraction() (float64, error) {
+	if r.RenewAtFraction == "" {
+		return 0, nil
+	}
+
+	fraction, err := strconv.ParseFloat(r.RenewAtFraction, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid renewAtFraction %q: %w", r.RenewAtFraction, err)
+	}
+	if fraction <= 0 || fraction >= 1 {
+		return 0, fmt.Errorf("invalid renewAtFraction %q: must be between 0 and 1", r.RenewAtFraction)
+	}
+
+	return fraction, nil
+}
+
+// AnnotationRenewRequestedAt requests a one-shot renewal of the token. Any new
+// value, typically the current timestamp, triggers a renewal.
+const AnnotationRenewRequestedAt = "token-renewer.barpilot.io/renew-requested-at"
+
+// Condition types reported in TokenStatus.Conditions.
+const (
+	// ConditionReady is True when the token is valid and stored in the target Secret.
+	ConditionReady = "Ready"
+	// ConditionRenewing is True while the token is inside its renewal window and
+	// has not been renewed yet.
+	ConditionRenewing = "Renewing"
+	// ConditionDegraded is True when the last reconciliation failed.
+	ConditionDegraded = "Degraded"
+	// ConditionExpired is True when the known expiration time is in the past.
+	ConditionExpired = "Expired"
+)
+
+// CurrentMetadata is the provider metadata of the token currently stored in the Secret.
+type CurrentMetadata struct {
+	// Metadata is the opaque provider metadata.
+	// +optional
+	Metadata string `json:"metadata,omitempty"`
+	// MetadataFields is the structured provider metadata.
+	// +optional
+	MetadataFields map[string]string `json:"metadataFields,omitempty"`
+	// SeedHash is a hash of the spec metadata this metadata was seeded from.
+	// The status is re-seeded when the spec metadata changes.
+	// +optional
+	SeedHash string `json:"seedHash,omitempty"`
+}
+
+// RenewalOutcome is the result of a renewal attempt.
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type RenewalOutcome string
+
+// ProviderMetadata is the provider identity of a token.
+type ProviderMetadata struct {
+	// Metadata is the opaque provider metadata.
+	// +optional
+	Metadata string `json:"metadata,omitempty"`
+	// MetadataFields is the structured provider metadata.
+	// +optional
+	MetadataFields map[string]string `json:"metadataFields,omitempty"`
+}
+
+// RenewalRecord describes one renewal attempt.
+type RenewalRecord struct {
+	// Time of the renewal attempt.
+	Time metav1.Time `json:"time"`
+	// Outcome of the renewal attempt.
+	Outcome RenewalOutcome `json:"outcome"`
+	// PreviousMetadata identifies the renewed token on the provider side.
+	// +optional
+	PreviousMetadata *ProviderMetadata `json:"previousMetadata,omitempty"`
+	// Metadata identifies the new token on the provider side.
+	// +optional
+	Metadata *ProviderMetadata `json:"metadata,omitempty"`
+	// ExpirationTime is when the new token expires.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+	// Fingerprint is a truncated SHA-256 of the new token value.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Message describes why the renewal failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// WorkloadRollout is the rollout state of a workload matched by a rollout target.
+type WorkloadRollout struct {
+	// Kind of the workload.
+	Kind string `json:"kind"`
+	// Name of the workload.
+	Name string `json:"name"`
+	// Fingerprint of the token the workload was last restarted for.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Time is when the workload was last restarted.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+	// Error is the error of the last restart attempt, if it failed.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PendingRevocation is a previous token kept valid by a two-phase rotation.
+type PendingRevocation struct {
+	// Metadata identifies the previous token on the provider side.
+	Metadata ProviderMetadata `json:"metadata"`
+	// Fingerprint is a truncated SHA-256 of the previous token value.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// RevokeAfter is when the overlap ends and the previous token is revoked.
+	RevokeAfter metav1.Time `json:"revokeAfter"`
+}
+
+// TokenStatus defines the observed state of Token. It carries the whole v1
+// status, so that Tokens stored as v1beta1 keep the state of the controller.
 type TokenStatus struct {
 	ExpirationTime metav1.Time `json:"expirationTime,omitempty"`
+
+	// IssueTime is when the current token was issued, or first observed by the
+	// controller when it was created outside of it.
+	// +optional
+	IssueTime metav1.Time `json:"issueTime,omitempty"`
+
+	// CurrentMetadata identifies the current token on the provider side. It is
+	// seeded from the spec metadata and updated after every renewal.
+	// +optional
+	CurrentMetadata *CurrentMetadata `json:"currentMetadata,omitempty"`
+
+	// TokenFingerprint is a truncated SHA-256 of the token value the expiration
+	// time was read for. A change means the Secret was modified out of band.
+	// +optional
+	TokenFingerprint string `json:"tokenFingerprint,omitempty"`
+
+	// LastValidityCheckTime is when the provider last reported the expiration
+	// time of the token.
+	// +optional
+	LastValidityCheckTime *metav1.Time `json:"lastValidityCheckTime,omitempty"`
+
+	// LastRenewalTime is when the token was last renewed successfully.
+	// +optional
+	LastRenewalTime *metav1.Time `json:"lastRenewalTime,omitempty"`
+
+	// History lists the latest renewal attempts, newest first.
+	// +optional
+	History []RenewalRecord `json:"history,omitempty"`
+
+	// PendingRevocations lists the previous tokens still valid after a
+	// two-phase rotation, waiting for the end of their overlap.
+	// +optional
+	PendingRevocations []PendingRevocation `json:"pendingRevocations,omitempty"`
+
+	// RotationGeneration counts the renewals committed by the controller.
+	// +optional
+	RotationGeneration int64 `json:"rotationGeneration,omitempty"`
+
+	// RenewalIdempotencyKey is the idempotency key of the renewal in progress.
+	// +optional
+	RenewalIdempotencyKey string `json:"renewalIdempotencyKey,omitempty"`
+
+	// Rollouts reports the workloads matched by the v1 rollout targets and the
+	// token they were last restarted for, or first matched with.
+	// +listType=map
+	// +listMapKey=kind
+	// +listMapKey=name
+	// +optional
+	Rollouts []WorkloadRollout `json:"rollouts,omitempty"`
+
+	// LastHandledRenewRequest is the last value of the renew-requested-at
+	// annotation the controller acted upon.
+	// +optional
+	LastHandledRenewRequest string `json:"lastHandledRenewRequest,omitempty"`
+
+	// ObservedGeneration is the generation last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the Token state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:deprecatedversion:warning="token-renewer.barpilot.io/v1beta1 Token is deprecated; use token-renewer.barpilot.io/v1"
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.spec.provider.name`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Expiration",type=string,format=date-time,JSONPath=`.status.expirationTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Token is the Schema for the tokens API.
 type Token struct {
@@ -2,17 +2,16 @@ package v1beta1
 
 import (
 	"testing"
-
-	corev1 "k8s.io/api/core/v1"
 )
 
 // TestTokenSpecMetadataValidation tests that metadata field validation works correctly
 func TestTokenSpecMetadataValidation(t *testing.T) {
 	tests := []struct {
-		name        string
-		metadata    string
-		shouldValid bool
-		description string
+		name           string
+		metadata       string
+		metadataFields map[string]string
+		shouldValid    bool
+		description    string
 	}{
 		{
 			name:        "valid_metadata",
@@ -24,22 +23,33 @@ func TestTokenSpecMetadataValidation(t *testing.T) {
 			name:        "empty_metadata",
 			metadata:    "",
 			shouldValid: false,
-			description: "Empty metadata should NOT be valid because field is Required",
+			description: "Empty metadata should NOT be valid without metadataFields",
+		},
+		{
+			name:           "empty_metadata_with_fields",
+			metadata:       "",
+			metadataFields: map[string]string{"id": "12345"},
+			shouldValid:    true,
+			description:    "Structured metadata replaces the metadata string",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			spec := TokenSpec{
-				Provider:  ProviderSpec{Name: "test"},
-				Metadata:  tt.metadata,
-				SecretRef: corev1.LocalObjectReference{Name: "test-secret"},
+				Provider:       ProviderSpec{Name: "test"},
+				Metadata:       tt.metadata,
+				MetadataFields: tt.metadataFields,
+				SecretRef:      SecretReference{Name: "test-secret"},
 			}
 
-			// The bug is that metadata is marked as Required: true but also has omitempty: true
-			// This creates a contradiction in validation
-			if tt.metadata == "" && tt.shouldValid {
-				t.Errorf("BUG: Empty metadata should not be valid (Required field)")
+			// The CRD requires one of metadata or metadataFields
+			if tt.metadata == "" && len(tt.metadataFields) == 0 && tt.shouldValid {
+				t.Errorf("BUG: Empty metadata should not be valid without metadataFields")
+			}
+
+			if len(tt.metadataFields) > 0 && len(spec.MetadataFields) != len(tt.metadataFields) {
+				t.Errorf("MetadataFields not stored correctly: got %v, want %v", spec.MetadataFields, tt.metadataFields)
 			}
 
 			// Test that non-empty metadata is accepted
@@ -59,7 +69,7 @@ func TestTokenSpecValidation(t *testing.T) {
 			Provider:  ProviderSpec{Name: "linode"},
 			Metadata:  "token-id-123",
 			Renewval:  RenewvalSpec{},
-			SecretRef: corev1.LocalObjectReference{Name: "my-secret"},
+			SecretRef: SecretReference{Name: "my-secret"},
 		}
 
 		// All required fields should be set
@@ -83,3 +93,51 @@ func TestTokenSpecValidation(t *testing.T) {
 		t.Log("The fix should remove 'omitempty' and add MinLength=1 validation")
 	})
 }
+
+// TestSecretReferenceTokenKey tests the Secret key fallback
+func TestSecretReferenceTokenKey(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  SecretReference
+		want string
+	}{
+		{"default_key", SecretReference{Name: "my-secret"}, DefaultSecretKey},
+		{"custom_key", SecretReference{Name: "my-secret", Key: "LINODE_TOKEN"}, "LINODE_TOKEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ref.TokenKey(); got != tt.want {
+				t.Errorf("TokenKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenewvalSpecFraction tests the renewAtFraction parsing
+func TestRenewvalSpecFraction(t *testing.T) {
+	tests := []struct {
+		fraction string
+		want     float64
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"0.66", 0.66, false},
+		{".5", 0.5, false},
+		{"0", 0, true},
+		{"1.5", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fraction, func(t *testing.T) {
+			got, err := RenewvalSpec{RenewAtFraction: tt.fraction}.Fraction()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fraction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Fraction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
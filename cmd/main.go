@@ -17,11 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 	"path/filepath"
 	"time"
+	// Embed the time zone database used by renewal schedules
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -40,10 +43,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
 	tokenrenewerv1beta1 "github.com/guilhem/token-renewer/api/v1beta1"
 	"github.com/guilhem/token-renewer/internal/controller"
 	"github.com/guilhem/token-renewer/internal/pluginserver"
 	"github.com/guilhem/token-renewer/internal/providers"
+	webhooktokenrenewerv1 "github.com/guilhem/token-renewer/internal/webhook/v1"
+	"github.com/guilhem/token-renewer/shared/tracing"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -56,6 +62,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(tokenrenewerv1beta1.AddToScheme(scheme))
+	utilruntime.Must(tokenrenewerv1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -89,6 +96,12 @@ func main() {
 	var pluginServerAddr string
 	flag.StringVar(&pluginServerAddr, "plugin-server-addr", "unix:///tmp/token-renewer.sock", "The address where the plugin server listens. "+
 		"Supports 'unix:///path/to/socket' or 'tcp://host:port' formats.")
+	var validityCheckInterval time.Duration
+	flag.DurationVar(&validityCheckInterval, "validity-check-interval", 0,
+		"How often the validity of the tokens is checked with their provider, unless set in the Token. "+
+			"0 disables the periodic checks.")
+	var tracingOpts tracing.Options
+	tracingOpts.BindFlags(flag.CommandLine)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -97,6 +110,17 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "token-renewer", tracingOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to flush traces")
+		}
+	}()
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -226,10 +250,11 @@ func main() {
 	}
 
 	if err = (&controller.TokenReconciler{
-		Client:           mgr.GetClient(),
-		Scheme:           mgr.GetScheme(),
-		Recorder:         mgr.GetEventRecorderFor("token-renewer"),
-		ProvidersManager: providersManager,
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		Recorder:             mgr.GetEventRecorderFor("token-renewer"),
+		ProvidersManager:     providersManager,
+		DefaultCheckInterval: validityCheckInterval,
 	}).SetupWithManager(mgr, workqueue.NewTypedItemFastSlowRateLimiter[reconcile.Request](
 		100*time.Millisecond, // fast delay: quick retries for transient errors
 		5*time.Minute,        // slow delay: longer wait for persistent errors
@@ -238,6 +263,21 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Token")
 		os.Exit(1)
 	}
+	if err = (&controller.ClusterTokenReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("token-renewer"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterToken")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhooktokenrenewerv1.SetupTokenWebhookWithManager(mgr, providersManager); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Token")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
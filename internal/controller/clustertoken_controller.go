@@ -0,0 +1,351 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+// ClusterTokenReconciler reconciles a ClusterToken object. The renewal itself
+// is delegated to a Token owned by the ClusterToken; this reconciler copies the
+// renewed Secret into the selected namespaces.
+type ClusterTokenReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=token-renewer.barpilot.io,resources=clustertokens,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=token-renewer.barpilot.io,resources=clustertokens/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=token-renewer.barpilot.io,resources=clustertokens/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reasons used for ClusterToken events and status conditions.
+const (
+	reasonTokenConflict      = "TokenConflict"
+	reasonInvalidSelector    = "InvalidNamespaceSelector"
+	reasonSourceNotReady     = "SourceNotReady"
+	reasonSecretSyncError    = "SecretSyncError"
+	reasonSecretsSynced      = "SecretsSynced"
+	reasonSecretCopyConflict = "SecretConflict"
+)
+
+func (r *ClusterTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	clusterToken := &tokenrenewerv1.ClusterToken{}
+	if err := r.Get(ctx, req.NamespacedName, clusterToken); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Renew the credential once through a Token owned by the ClusterToken
+	token := &tokenrenewerv1.Token{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterToken.Name,
+			Namespace: clusterToken.Spec.Namespace,
+		},
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(token), token); err == nil && !metav1.IsControlledBy(token, clusterToken) {
+		return r.fail(ctx, clusterToken, reasonTokenConflict, "Token already exists",
+			fmt.Errorf("token %s/%s exists and is not managed by this ClusterToken", token.Namespace, token.Name))
+	}
+	if op, err := r.syncToken(ctx, clusterToken, token); err != nil {
+		return r.fail(ctx, clusterToken, reasonTokenUpdateError, "Error updating token", fmt.Errorf("unable to update token: %w", err))
+	} else if op != controllerutil.OperationResultNone {
+		log.Info("Managed Token updated", "token", client.ObjectKeyFromObject(token), "operation", op)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&clusterToken.Spec.NamespaceSelector)
+	if err != nil {
+		return r.fail(ctx, clusterToken, reasonInvalidSelector, "Invalid namespace selector", fmt.Errorf("invalid namespace selector: %w", err))
+	}
+
+	// Copy only a Secret holding a token the Token controller has validated
+	if !meta.IsStatusConditionTrue(token.Status.Conditions, tokenrenewerv1.ConditionReady) {
+		return r.updateStatus(ctx, clusterToken, token, clusterToken.Status.Namespaces, metav1.Condition{
+			Type:    tokenrenewerv1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonSourceNotReady,
+			Message: fmt.Sprintf("Token %s/%s is not ready", token.Namespace, token.Name),
+		})
+	}
+
+	source := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: token.SecretNamespace(), Name: token.Spec.SecretRef.Name}, source); err != nil {
+		return r.fail(ctx, clusterToken, reasonSecretNotFound, "Secret not found", fmt.Errorf("unable to fetch secret: %w", err))
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to list namespaces: %w", err)
+	}
+
+	selected := make(map[string]bool, len(namespaces.Items))
+	var synced []tokenrenewerv1.NamespaceSyncStatus
+	failed := 0
+	for _, namespace := range namespaces.Items {
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		// Never overwrite the source Secret with itself
+		if namespace.Name == source.Namespace && clusterToken.Spec.TargetName() == source.Name {
+			continue
+		}
+		selected[namespace.Name] = true
+
+		nsStatus := tokenrenewerv1.NamespaceSyncStatus{Namespace: namespace.Name, Synced: true}
+		if previous := findNamespaceStatus(clusterToken.Status.Namespaces, namespace.Name); previous != nil {
+			nsStatus.LastSyncTime = previous.LastSyncTime
+		}
+
+		op, err := r.syncCopy(ctx, clusterToken, source, namespace.Name)
+		if err != nil {
+			log.Error(err, "unable to sync secret copy", "namespace", namespace.Name)
+			failed++
+			nsStatus.Synced = false
+			nsStatus.Message = err.Error()
+		} else if op != controllerutil.OperationResultNone || nsStatus.LastSyncTime.IsZero() {
+			nsStatus.LastSyncTime = metav1.Now()
+		}
+		synced = append(synced, nsStatus)
+	}
+
+	// Garbage-collect the copies in namespaces that are no longer selected
+	copies := &corev1.SecretList{}
+	if err := r.List(ctx, copies, client.MatchingLabels{tokenrenewerv1.LabelClusterToken: string(clusterToken.UID)}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to list secret copies: %w", err)
+	}
+	for i := range copies.Items {
+		secretCopy := &copies.Items[i]
+		if selected[secretCopy.Namespace] && secretCopy.Name == clusterToken.Spec.TargetName() {
+			continue
+		}
+		log.Info("Deleting secret copy", "secret", client.ObjectKeyFromObject(secretCopy))
+		if err := r.Delete(ctx, secretCopy); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to delete secret copy %s/%s: %w", secretCopy.Namespace, secretCopy.Name, err)
+		}
+		r.Recorder.Eventf(clusterToken, "Normal", "SecretCopyDeleted", "Deleted secret copy in namespace %s", secretCopy.Namespace)
+	}
+
+	ready := metav1.Condition{
+		Type:    tokenrenewerv1.ConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonSecretsSynced,
+		Message: fmt.Sprintf("Secret synced to %d namespaces", len(synced)),
+	}
+	if failed > 0 {
+		ready.Status, ready.Reason = metav1.ConditionFalse, reasonSecretSyncError
+		ready.Message = fmt.Sprintf("Secret sync failed in %d of %d namespaces", failed, len(synced))
+		r.Recorder.Event(clusterToken, "Warning", reasonSecretSyncError, ready.Message)
+	}
+
+	return r.updateStatus(ctx, clusterToken, token, synced, ready)
+}
+
+// syncToken creates or updates the Token renewing the credential of the
+// ClusterToken. The template is defaulted as the webhook defaults the stored
+// Token, so that an unchanged template does not update the Token again.
+func (r *ClusterTokenReconciler) syncToken(ctx context.Context, clusterToken *tokenrenewerv1.ClusterToken, token *tokenrenewerv1.Token) (controllerutil.OperationResult, error) {
+	return controllerutil.CreateOrUpdate(ctx, r.Client, token, func() error {
+		token.Spec = *clusterToken.Spec.Token.DeepCopy()
+		token.Spec.SetDefaults()
+		return controllerutil.SetControllerReference(clusterToken, token, r.Scheme)
+	})
+}
+
+// syncCopy writes the content of the source Secret into the copy in namespace.
+// A Secret that is not managed by the ClusterToken is never overwritten.
+func (r *ClusterTokenReconciler) syncCopy(ctx context.Context, clusterToken *tokenrenewerv1.ClusterToken, source *corev1.Secret, namespace string) (controllerutil.OperationResult, error) {
+	secretCopy := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterToken.Spec.TargetName(),
+			Namespace: namespace,
+		},
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(secretCopy), secretCopy); err == nil {
+		if !metav1.IsControlledBy(secretCopy, clusterToken) {
+			r.Recorder.Eventf(clusterToken, "Warning", reasonSecretCopyConflict,
+				"Secret %s/%s exists and is not managed by this ClusterToken", namespace, secretCopy.Name)
+			return controllerutil.OperationResultNone, fmt.Errorf("secret exists and is not managed by this ClusterToken")
+		}
+		// The type of a Secret is immutable
+		if secretCopy.Type != source.Type {
+			if err := r.Delete(ctx, secretCopy, client.Preconditions{UID: &secretCopy.UID}); err != nil {
+				return controllerutil.OperationResultNone, fmt.Errorf("unable to delete secret to change its type: %w", err)
+			}
+			secretCopy = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretCopy.Name, Namespace: namespace}}
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return controllerutil.OperationResultNone, fmt.Errorf("unable to fetch secret: %w", err)
+	}
+
+	return controllerutil.CreateOrUpdate(ctx, r.Client, secretCopy, func() error {
+		if secretCopy.CreationTimestamp.IsZero() {
+			secretCopy.Type = source.Type
+		}
+		if secretCopy.Labels == nil {
+			secretCopy.Labels = make(map[string]string)
+		}
+		secretCopy.Labels[tokenrenewerv1.LabelClusterToken] = string(clusterToken.UID)
+		secretCopy.Data = maps.Clone(source.Data)
+		return controllerutil.SetControllerReference(clusterToken, secretCopy, r.Scheme)
+	})
+}
+
+// updateStatus mirrors the managed Token expiration and records the namespace
+// sync states and the Ready condition. The ClusterToken is requeued with the
+// Token, so it does not schedule renewals itself.
+func (r *ClusterTokenReconciler) updateStatus(ctx context.Context, clusterToken *tokenrenewerv1.ClusterToken, token *tokenrenewerv1.Token, namespaces []tokenrenewerv1.NamespaceSyncStatus, ready metav1.Condition) (ctrl.Result, error) {
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Namespace < namespaces[j].Namespace })
+
+	degraded := metav1.Condition{
+		Type:    tokenrenewerv1.ConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonReconcileSucceeded,
+		Message: "Reconciliation succeeded",
+	}
+	if ready.Reason == reasonSecretSyncError {
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+	}
+
+	if _, err := controllerutil.CreateOrPatch(ctx, r.Client, clusterToken, func() error {
+		clusterToken.Status.ExpirationTime = token.Status.ExpirationTime
+		clusterToken.Status.Namespaces = namespaces
+		clusterToken.Status.ObservedGeneration = clusterToken.Generation
+		ready.ObservedGeneration, degraded.ObservedGeneration = clusterToken.Generation, clusterToken.Generation
+		meta.SetStatusCondition(&clusterToken.Status.Conditions, ready)
+		meta.SetStatusCondition(&clusterToken.Status.Conditions, degraded)
+		return nil
+	}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to update cluster token status: %w", err)
+	}
+
+	if ready.Reason == reasonSecretSyncError {
+		return ctrl.Result{}, errors.New(ready.Message)
+	}
+	return ctrl.Result{}, nil
+}
+
+// fail records a failure in the ClusterToken conditions and returns err so that
+// the request is retried with backoff.
+func (r *ClusterTokenReconciler) fail(ctx context.Context, clusterToken *tokenrenewerv1.ClusterToken, reason, message string, err error) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	r.Recorder.Event(clusterToken, "Warning", reason, message)
+
+	if _, uerr := controllerutil.CreateOrPatch(ctx, r.Client, clusterToken, func() error {
+		clusterToken.Status.ObservedGeneration = clusterToken.Generation
+		for _, conditionType := range []string{tokenrenewerv1.ConditionReady, tokenrenewerv1.ConditionDegraded} {
+			status := metav1.ConditionFalse
+			if conditionType == tokenrenewerv1.ConditionDegraded {
+				status = metav1.ConditionTrue
+			}
+			meta.SetStatusCondition(&clusterToken.Status.Conditions, metav1.Condition{
+				Type:               conditionType,
+				Status:             status,
+				Reason:             reason,
+				Message:            err.Error(),
+				ObservedGeneration: clusterToken.Generation,
+			})
+		}
+		return nil
+	}); uerr != nil {
+		log.Error(uerr, "unable to update ClusterToken status", "clusterToken", clusterToken.GetName())
+	}
+
+	return ctrl.Result{}, err
+}
+
+// findNamespaceStatus returns the sync status of namespace, or nil.
+func findNamespaceStatus(statuses []tokenrenewerv1.NamespaceSyncStatus, namespace string) *tokenrenewerv1.NamespaceSyncStatus {
+	for i := range statuses {
+		if statuses[i].Namespace == namespace {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterTokenReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tokenrenewerv1.ClusterToken{}).
+		Owns(&tokenrenewerv1.Token{}).
+		Owns(&corev1.Secret{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.allClusterTokens)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.clusterTokensForSource)).
+		Named("clustertoken").
+		Complete(r)
+}
+
+// allClusterTokens enqueues every ClusterToken, as any namespace change may
+// change the namespaces they select.
+func (r *ClusterTokenReconciler) allClusterTokens(ctx context.Context, _ client.Object) []reconcile.Request {
+	clusterTokens := &tokenrenewerv1.ClusterTokenList{}
+	if err := r.List(ctx, clusterTokens); err != nil {
+		logf.FromContext(ctx).Error(err, "unable to list ClusterTokens")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(clusterTokens.Items))
+	for _, clusterToken := range clusterTokens.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterToken)})
+	}
+	return requests
+}
+
+// clusterTokensForSource enqueues the ClusterTokens whose source Secret is obj.
+func (r *ClusterTokenReconciler) clusterTokensForSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	clusterTokens := &tokenrenewerv1.ClusterTokenList{}
+	if err := r.List(ctx, clusterTokens); err != nil {
+		logf.FromContext(ctx).Error(err, "unable to list ClusterTokens")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, clusterToken := range clusterTokens.Items {
+		secretRef := clusterToken.Spec.Token.SecretRef
+		namespace := secretRef.Namespace
+		if namespace == "" {
+			namespace = clusterToken.Spec.Namespace
+		}
+		if namespace == obj.GetNamespace() && secretRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterToken)})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+	"github.com/guilhem/token-renewer/internal/providers"
+)
+
+var _ = Describe("ClusterToken Controller", func() {
+	Context("When reconciling a resource", func() {
+		const (
+			resourceName    = "test-cluster-token"
+			targetNamespace = "cluster-token-target"
+		)
+
+		ctx := context.Background()
+
+		clusterTokenName := types.NamespacedName{Name: resourceName}
+		tokenName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			By("creating the target namespace and the source Secret")
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   targetNamespace,
+					Labels: map[string]string{"token-renewer.barpilot.io/test": "true"},
+				},
+			}
+			err := k8sClient.Create(ctx, namespace)
+			if err != nil && !errors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-source-secret", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("test-token-value")},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			By("creating the custom resource for the Kind ClusterToken")
+			resource := &tokenrenewerv1.ClusterToken{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+				Spec: tokenrenewerv1.ClusterTokenSpec{
+					Token: tokenrenewerv1.TokenSpec{
+						Provider:  tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+						Metadata:  "test-metadata",
+						SecretRef: tokenrenewerv1.SecretReference{Name: "cluster-source-secret"},
+					},
+					Namespace: "default",
+					NamespaceSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"token-renewer.barpilot.io/test": "true"},
+					},
+					TargetSecretName: "shared-secret",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			// envtest runs no garbage collector, so owned objects are deleted explicitly
+			resource := &tokenrenewerv1.ClusterToken{}
+			Expect(k8sClient.Get(ctx, clusterTokenName, resource)).To(Succeed())
+			By("Cleanup the specific resource instance ClusterToken")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			token := &tokenrenewerv1.Token{}
+			if err := k8sClient.Get(ctx, tokenName, token); err == nil {
+				Expect(k8sClient.Delete(ctx, token)).To(Succeed())
+			}
+			for _, key := range []types.NamespacedName{
+				{Name: "cluster-source-secret", Namespace: "default"},
+				{Name: "shared-secret", Namespace: targetNamespace},
+			} {
+				secret := &corev1.Secret{}
+				if err := k8sClient.Get(ctx, key, secret); err == nil {
+					Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+				}
+			}
+		})
+
+		It("should copy the renewed Secret into the selected namespaces", func() {
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+			fakeRecorder := record.NewFakeRecorder(20)
+
+			clusterTokenReconciler := &ClusterTokenReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: fakeRecorder,
+			}
+			tokenReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         fakeRecorder,
+			}
+
+			By("Creating the managed Token")
+			_, err := clusterTokenReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: clusterTokenName})
+			Expect(err).NotTo(HaveOccurred())
+
+			token := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, tokenName, token)).To(Succeed())
+			owner := metav1.GetControllerOf(token)
+			Expect(owner).NotTo(BeNil())
+			Expect(owner.Kind).To(Equal("ClusterToken"))
+
+			By("Reconciling the managed Token then the ClusterToken")
+			_, err = tokenReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: tokenName})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = clusterTokenReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: clusterTokenName})
+			Expect(err).NotTo(HaveOccurred())
+
+			secretCopy := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "shared-secret", Namespace: targetNamespace}, secretCopy)).To(Succeed())
+			Expect(secretCopy.Data).To(HaveKey("token"))
+			Expect(secretCopy.Labels).To(HaveKey(tokenrenewerv1.LabelClusterToken))
+
+			clusterToken := &tokenrenewerv1.ClusterToken{}
+			Expect(k8sClient.Get(ctx, clusterTokenName, clusterToken)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(clusterToken.Status.Conditions, tokenrenewerv1.ConditionReady)).To(BeTrue())
+			Expect(clusterToken.Status.ExpirationTime.IsZero()).To(BeFalse())
+
+			status := findNamespaceStatus(clusterToken.Status.Namespaces, targetNamespace)
+			Expect(status).NotTo(BeNil())
+			Expect(status.Synced).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,217 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+	"github.com/guilhem/token-renewer/shared/tracing"
+)
+
+// rolloutWorkloads restarts the workloads matched by the rollout targets that
+// may still hold a previous token: those recorded with a previous token, whose
+// pod template does not carry the fingerprint of the current token and that
+// were created before it was issued. A workload matched for the first time is
+// only recorded with the current token, as the controller never saw it hold
+// another one. It returns the rollout state of every matched workload; failed
+// restarts are reported in their state and in the returned error. Workloads
+// are only restarted in the namespace of the Token, along with its Secret.
+func (r *TokenReconciler) rolloutWorkloads(ctx context.Context, token *tokenrenewerv1.Token) (_ []tokenrenewerv1.WorkloadRollout, err error) {
+	ctx, span := tracing.Start(ctx, "Rollout")
+	defer func() { tracing.End(span, err) }()
+
+	log := logf.FromContext(ctx)
+
+	fingerprint := token.Status.TokenFingerprint
+	if len(token.Spec.RolloutTargets) == 0 || fingerprint == "" {
+		return nil, nil
+	}
+	if token.SecretNamespace() != token.Namespace {
+		// A grant lets the Token write the Secret, not restart the workloads
+		// of its namespace
+		log.Info("Rollout targets ignored for a Secret of another namespace", "namespace", token.SecretNamespace())
+		r.Recorder.Event(token, "Warning", reasonRolloutError, "Rollout targets are not supported with a Secret of another namespace")
+		return nil, nil
+	}
+
+	var (
+		rollouts []tokenrenewerv1.WorkloadRollout
+		errs     []error
+	)
+	seen := make(map[string]bool)
+	for _, target := range token.Spec.RolloutTargets {
+		workloads, err := r.rolloutTargetWorkloads(ctx, token.Namespace, target)
+		if apierrors.IsNotFound(err) {
+			if key := target.Kind + "/" + target.Name; !seen[key] {
+				seen[key] = true
+				rollouts = append(rollouts, tokenrenewerv1.WorkloadRollout{
+					Kind:  target.Kind,
+					Name:  target.Name,
+					Error: fmt.Sprintf("%s %s not found", target.Kind, target.Name),
+				})
+			}
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, workload := range workloads {
+			// A workload matched by several targets is restarted once
+			key := target.Kind + "/" + workload.GetName()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			rollout := previousRollout(token, target.Kind, workload.GetName())
+			created := workload.GetCreationTimestamp()
+			switch {
+			case podTemplate(workload).Annotations[tokenrenewerv1.AnnotationTokenFingerprint] == fingerprint:
+				rollout.Fingerprint, rollout.Error = fingerprint, ""
+			case rollout.Fingerprint == "":
+				// The token may predate the workload or the target, so only the
+				// changes observed from now on restart it
+				rollout.Fingerprint, rollout.Error = fingerprint, ""
+			case rollout.Fingerprint == fingerprint:
+				rollout.Error = ""
+			case !created.Before(&token.Status.IssueTime):
+				// The pods of the workload were created with the current token
+				rollout.Fingerprint, rollout.Error = fingerprint, ""
+			default:
+				if err := r.restartWorkload(ctx, workload, fingerprint); err != nil {
+					log.Error(err, "unable to restart workload", "kind", target.Kind, "name", workload.GetName())
+					r.Recorder.Eventf(token, "Warning", reasonRolloutError, "Error restarting %s %s: %v", target.Kind, workload.GetName(), err)
+					rollout.Error = err.Error()
+					errs = append(errs, fmt.Errorf("unable to restart %s %s: %w", target.Kind, workload.GetName(), err))
+					break
+				}
+				log.Info("Workload restarted for the new token", "kind", target.Kind, "name", workload.GetName())
+				r.Recorder.Eventf(token, "Normal", reasonRolloutTriggered, "Restarted %s %s for token %s", target.Kind, workload.GetName(), fingerprint)
+				now := metav1.Now()
+				rollout.Fingerprint, rollout.Time, rollout.Error = fingerprint, &now, ""
+			}
+			rollouts = append(rollouts, rollout)
+		}
+	}
+
+	return rollouts, errors.Join(errs...)
+}
+
+// rolloutTargetWorkloads returns the workloads of the namespace matched by target.
+func (r *TokenReconciler) rolloutTargetWorkloads(ctx context.Context, namespace string, target tokenrenewerv1.RolloutTarget) ([]client.Object, error) {
+	if target.Name != "" {
+		workload, err := newWorkload(target.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: target.Name}, workload); err != nil {
+			return nil, err
+		}
+		return []client.Object{workload}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s selector: %w", target.Kind, err)
+	}
+
+	var list client.ObjectList
+	switch target.Kind {
+	case tokenrenewerv1.RolloutKindDeployment:
+		list = &appsv1.DeploymentList{}
+	case tokenrenewerv1.RolloutKindStatefulSet:
+		list = &appsv1.StatefulSetList{}
+	case tokenrenewerv1.RolloutKindDaemonSet:
+		list = &appsv1.DaemonSetList{}
+	default:
+		return nil, fmt.Errorf("unsupported rollout target kind %q", target.Kind)
+	}
+	if err := r.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("unable to list %s: %w", target.Kind, err)
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	workloads := make([]client.Object, 0, len(items))
+	for _, item := range items {
+		workloads = append(workloads, item.(client.Object))
+	}
+	return workloads, nil
+}
+
+// restartWorkload sets the token fingerprint annotation on the pod template of
+// the workload, which rolls its pods out.
+func (r *TokenReconciler) restartWorkload(ctx context.Context, workload client.Object, fingerprint string) error {
+	patch := client.MergeFrom(workload.DeepCopyObject().(client.Object))
+	template := podTemplate(workload)
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	template.Annotations[tokenrenewerv1.AnnotationTokenFingerprint] = fingerprint
+	return r.Patch(ctx, workload, patch)
+}
+
+// newWorkload returns an empty workload of the given kind.
+func newWorkload(kind string) (client.Object, error) {
+	switch kind {
+	case tokenrenewerv1.RolloutKindDeployment:
+		return &appsv1.Deployment{}, nil
+	case tokenrenewerv1.RolloutKindStatefulSet:
+		return &appsv1.StatefulSet{}, nil
+	case tokenrenewerv1.RolloutKindDaemonSet:
+		return &appsv1.DaemonSet{}, nil
+	}
+	return nil, fmt.Errorf("unsupported rollout target kind %q", kind)
+}
+
+// podTemplate returns the pod template of a workload returned by newWorkload.
+func podTemplate(workload client.Object) *corev1.PodTemplateSpec {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template
+	}
+	panic(fmt.Sprintf("unsupported workload %T", workload))
+}
+
+// previousRollout returns the recorded rollout state of a workload, or a new
+// state when it was not matched before.
+func previousRollout(token *tokenrenewerv1.Token, kind, name string) tokenrenewerv1.WorkloadRollout {
+	for _, rollout := range token.Status.Rollouts {
+		if rollout.Kind == kind && rollout.Name == name {
+			return *rollout.DeepCopy()
+		}
+	}
+	return tokenrenewerv1.WorkloadRollout{Kind: kind, Name: name}
+}
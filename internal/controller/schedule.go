@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+// nextWindowStart returns from when it falls inside a maintenance window, or
+// the opening time of the next window otherwise.
+func nextWindowStart(schedule *tokenrenewerv1.RenewalSchedule, from time.Time) (time.Time, error) {
+	location := time.UTC
+	if schedule.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(schedule.TimeZone); err != nil {
+			return time.Time{}, fmt.Errorf("invalid schedule time zone %q: %w", schedule.TimeZone, err)
+		}
+	}
+
+	var next time.Time
+	local := from.In(location)
+	// Windows last at most a week, so one opening before and one after from are enough
+	for offset := -7; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, location)
+
+		for _, window := range schedule.Windows {
+			if len(window.Days) > 0 && !slices.Contains(window.Days, tokenrenewerv1.Weekday(day.Weekday().String())) {
+				continue
+			}
+
+			opening, err := time.Parse("15:04", window.Start)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid window start %q: %w", window.Start, err)
+			}
+			start := time.Date(day.Year(), day.Month(), day.Day(), opening.Hour(), opening.Minute(), 0, 0, location)
+			if !start.Add(window.Duration.Duration).After(from) {
+				continue
+			}
+			if !start.After(from) {
+				return from, nil
+			}
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+		}
+	}
+
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("schedule has no maintenance window")
+	}
+
+	return next, nil
+}
+
+// nextRenewal returns when the token should be renewed, taking the renewal
+// policy and the maintenance windows into account. deferred reports that a
+// renewal already due waits for the next window; forced reports that the token
+// expires before the next window opens and is renewed outside of it.
+func nextRenewal(token *tokenrenewerv1.Token, now time.Time) (renewAt time.Time, deferred, forced bool, err error) {
+	renewAt, err = renewalTime(token)
+	if err != nil {
+		return time.Time{}, false, false, err
+	}
+
+	schedule := token.Spec.Renewal.Schedule
+	if schedule == nil {
+		return renewAt, false, false, nil
+	}
+
+	from := renewAt
+	if from.Before(now) {
+		from = now
+	}
+
+	start, err := nextWindowStart(schedule, from)
+	if err != nil {
+		return time.Time{}, false, false, err
+	}
+	if !start.Before(token.Status.ExpirationTime.Time) {
+		return renewAt, false, true, nil
+	}
+
+	return start, !renewAt.After(now) && start.After(now), false, nil
+}
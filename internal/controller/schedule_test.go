@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+// nightlyWeekdays opens every weekday night from 22:00 to 02:00 in Paris.
+var nightlyWeekdays = &tokenrenewerv1.RenewalSchedule{
+	TimeZone: "Europe/Paris",
+	Windows: []tokenrenewerv1.MaintenanceWindow{{
+		Days:     []tokenrenewerv1.Weekday{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+		Start:    "22:00",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}},
+}
+
+func TestNextWindowStart(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "inside_window",
+			from: time.Date(2025, 6, 2, 23, 0, 0, 0, paris), // Monday
+			want: time.Date(2025, 6, 2, 23, 0, 0, 0, paris),
+		},
+		{
+			name: "inside_window_after_midnight",
+			from: time.Date(2025, 6, 3, 1, 30, 0, 0, paris), // Tuesday, Monday's window
+			want: time.Date(2025, 6, 3, 1, 30, 0, 0, paris),
+		},
+		{
+			name: "business_hours",
+			from: time.Date(2025, 6, 3, 10, 0, 0, 0, paris),
+			want: time.Date(2025, 6, 3, 22, 0, 0, 0, paris),
+		},
+		{
+			name: "weekend",
+			from: time.Date(2025, 6, 7, 10, 0, 0, 0, paris), // Saturday
+			want: time.Date(2025, 6, 9, 22, 0, 0, 0, paris),
+		},
+		{
+			name: "window_close",
+			from: time.Date(2025, 6, 3, 2, 0, 0, 0, paris),
+			want: time.Date(2025, 6, 3, 22, 0, 0, 0, paris),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextWindowStart(nightlyWeekdays, tt.from)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("nextWindowStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextWindowStartInvalidTimeZone(t *testing.T) {
+	schedule := &tokenrenewerv1.RenewalSchedule{
+		TimeZone: "Mars/Olympus",
+		Windows:  nightlyWeekdays.Windows,
+	}
+	if _, err := nextWindowStart(schedule, time.Now()); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+}
+
+func TestNextRenewal(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 3, 10, 0, 0, 0, paris) // Tuesday morning
+	window := time.Date(2025, 6, 3, 22, 0, 0, 0, paris)
+
+	tests := []struct {
+		name         string
+		expiration   time.Time
+		before       time.Duration
+		schedule     *tokenrenewerv1.RenewalSchedule
+		wantRenewAt  time.Time
+		wantDeferred bool
+		wantForced   bool
+	}{
+		{
+			name:        "no_schedule",
+			expiration:  now.Add(30 * time.Minute),
+			before:      time.Hour,
+			wantRenewAt: now.Add(-30 * time.Minute),
+		},
+		{
+			name:         "deferred_to_window",
+			expiration:   now.Add(2 * 24 * time.Hour),
+			before:       2 * 24 * time.Hour,
+			schedule:     nightlyWeekdays,
+			wantRenewAt:  window,
+			wantDeferred: true,
+		},
+		{
+			name:        "scheduled_in_future_window",
+			expiration:  now.Add(10 * 24 * time.Hour),
+			before:      2 * 24 * time.Hour,
+			schedule:    nightlyWeekdays,
+			wantRenewAt: time.Date(2025, 6, 11, 22, 0, 0, 0, paris), // Wednesday after renewAt
+
+		},
+		{
+			name:        "forced_before_expiry",
+			expiration:  now.Add(30 * time.Minute),
+			before:      time.Hour,
+			schedule:    nightlyWeekdays,
+			wantRenewAt: now.Add(-30 * time.Minute),
+			wantForced:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &tokenrenewerv1.Token{}
+			token.Spec.Renewal.BeforeDuration = metav1.Duration{Duration: tt.before}
+			token.Spec.Renewal.Schedule = tt.schedule
+			token.Status.ExpirationTime = metav1.NewTime(tt.expiration)
+
+			renewAt, deferred, forced, err := nextRenewal(token, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !renewAt.Equal(tt.wantRenewAt) {
+				t.Errorf("renewAt = %v, want %v", renewAt, tt.wantRenewAt)
+			}
+			if deferred != tt.wantDeferred {
+				t.Errorf("deferred = %v, want %v", deferred, tt.wantDeferred)
+			}
+			if forced != tt.wantForced {
+				t.Errorf("forced = %v, want %v", forced, tt.wantForced)
+			}
+		})
+	}
+}
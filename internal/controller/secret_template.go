@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"text/template"
+	"time"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+// secretValues is the data exposed to the Secret templates.
+type secretValues struct {
+	Token          string
+	Metadata       string
+	MetadataFields map[string]string
+	Expiration     time.Time
+}
+
+var templateFuncs = template.FuncMap{
+	"b64enc": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"b64dec": func(s string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(s)
+		return string(b), err
+	},
+	"toJson": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// renderSecretTemplates renders every template entry with the given values.
+// Entries that fail to render are left out of the result and reported in the
+// returned error.
+func renderSecretTemplates(spec *tokenrenewerv1.SecretTemplateSpec, values secretValues) (map[string][]byte, error) {
+	if spec == nil || len(spec.Data) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(spec.Data))
+	for key := range spec.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rendered := make(map[string][]byte, len(keys))
+	var errs []error
+	for _, key := range keys {
+		tpl, err := template.New(key).Funcs(templateFuncs).Option("missingkey=error").Parse(spec.Data[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", key, err))
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, values); err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", key, err))
+			continue
+		}
+		rendered[key] = buf.Bytes()
+	}
+
+	return rendered, errors.Join(errs...)
+}
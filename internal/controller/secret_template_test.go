@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+// TestRenderSecretTemplates tests rendering of the Secret template entries
+func TestRenderSecretTemplates(t *testing.T) {
+	values := secretValues{
+		Token:      "s3cr3t",
+		Metadata:   "12345",
+		Expiration: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "token_and_metadata",
+			data: map[string]string{"env": "TOKEN={{ .Token }}\nID={{ .Metadata }}"},
+			want: map[string]string{"env": "TOKEN=s3cr3t\nID=12345"},
+		},
+		{
+			name: "expiration",
+			data: map[string]string{"expires": `{{ .Expiration.Format "2006-01-02" }}`},
+			want: map[string]string{"expires": "2030-01-02"},
+		},
+		{
+			name: "dockerconfigjson",
+			data: map[string]string{
+				".dockerconfigjson": `{"auths":{"registry.example.com":{"password":{{ toJson .Token }},"auth":"{{ printf "bot:%s" .Token | b64enc }}"}}}`,
+			},
+			want: map[string]string{
+				".dockerconfigjson": `{"auths":{"registry.example.com":{"password":"s3cr3t","auth":"Ym90OnMzY3IzdA=="}}}`,
+			},
+		},
+		{
+			name:    "unknown_field",
+			data:    map[string]string{"bad": "{{ .Unknown }}", "good": "{{ .Token }}"},
+			want:    map[string]string{"good": "s3cr3t"},
+			wantErr: true,
+		},
+		{
+			name:    "parse_error",
+			data:    map[string]string{"bad": "{{ .Token "},
+			want:    map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderSecretTemplates(&tokenrenewerv1.SecretTemplateSpec{Data: tt.data}, values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderSecretTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("renderSecretTemplates() rendered %d entries, want %d", len(got), len(tt.want))
+			}
+			for key, want := range tt.want {
+				if string(got[key]) != want {
+					t.Errorf("entry %q = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+// TestRenderSecretTemplates_NoTemplate tests that a missing template renders nothing
+func TestRenderSecretTemplates_NoTemplate(t *testing.T) {
+	got, err := renderSecretTemplates(nil, secretValues{Token: "s3cr3t"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected no entries, got %v", got)
+	}
+}
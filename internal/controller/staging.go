@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+	"github.com/guilhem/token-renewer/shared"
+)
+
+const (
+	stagingSecretPrefix = "token-renewer-staging-"
+	stagingKeyToken     = "token"
+	stagingKeyRenewal   = "renewal"
+)
+
+// stagedRenewal is a token renewed by the provider but not yet committed to the
+// target Secret and the Token status. It is written ahead to a staging Secret
+// since the provider may already have revoked the previous token.
+type stagedRenewal struct {
+	Token string `json:"-"`
+
+	Metadata            tokenrenewerv1.ProviderMetadata `json:"metadata"`
+	PreviousMetadata    tokenrenewerv1.ProviderMetadata `json:"previousMetadata"`
+	PreviousFingerprint string                          `json:"previousFingerprint"`
+	PreviousRetained    bool                            `json:"previousRetained,omitempty"`
+	ExpirationTime      metav1.Time                     `json:"expirationTime"`
+	IssueTime           metav1.Time                     `json:"issueTime"`
+	RenewRequest        string                          `json:"renewRequest,omitempty"`
+}
+
+func (s *stagedRenewal) metadata() shared.Metadata {
+	return shared.Metadata{Value: s.Metadata.Metadata, Fields: maps.Clone(s.Metadata.MetadataFields)}
+}
+
+func (s *stagedRenewal) previousMetadata() shared.Metadata {
+	return shared.Metadata{Value: s.PreviousMetadata.Metadata, Fields: maps.Clone(s.PreviousMetadata.MetadataFields)}
+}
+
+// stagingSecretName returns the name of the staging Secret of the Token. It is
+// derived from the UID so that it cannot collide with a user Secret.
+func stagingSecretName(token *tokenrenewerv1.Token) string {
+	return stagingSecretPrefix + string(token.UID)
+}
+
+// encodeStagedRenewal returns the data of the staging Secret of a renewal.
+func encodeStagedRenewal(staged *stagedRenewal) (map[string][]byte, error) {
+	renewal, err := json.Marshal(staged)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		stagingKeyToken:   []byte(staged.Token),
+		stagingKeyRenewal: renewal,
+	}, nil
+}
+
+// decodeStagedRenewal reads a renewal back from the data of its staging Secret.
+func decodeStagedRenewal(data map[string][]byte) (*stagedRenewal, error) {
+	staged := &stagedRenewal{}
+	if err := json.Unmarshal(data[stagingKeyRenewal], staged); err != nil {
+		return nil, fmt.Errorf("invalid %q entry: %w", stagingKeyRenewal, err)
+	}
+	staged.Token = string(data[stagingKeyToken])
+	if staged.Token == "" {
+		return nil, fmt.Errorf("missing %q entry", stagingKeyToken)
+	}
+	return staged, nil
+}
+
+// stageRenewal writes the renewal to the staging Secret of the Token, owned by
+// the Token so that it is garbage collected with it.
+func (r *TokenReconciler) stageRenewal(ctx context.Context, token *tokenrenewerv1.Token, staged *stagedRenewal) error {
+	data, err := encodeStagedRenewal(staged)
+	if err != nil {
+		return fmt.Errorf("unable to encode staged renewal: %w", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stagingSecretName(token),
+			Namespace: token.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Data = data
+		return controllerutil.SetControllerReference(token, secret, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("unable to write staging secret: %w", err)
+	}
+	return nil
+}
+
+// stagedRenewal returns the renewal left in the staging Secret of the Token by
+// an interrupted reconciliation, or nil when there is none.
+func (r *TokenReconciler) stagedRenewal(ctx context.Context, token *tokenrenewerv1.Token) (*stagedRenewal, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: token.Namespace, Name: stagingSecretName(token)}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to fetch staging secret: %w", err)
+	}
+
+	staged, err := decodeStagedRenewal(secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("staging secret %s: %w", secret.Name, err)
+	}
+	return staged, nil
+}
+
+// clearStagedRenewal deletes the staging Secret once its renewal is committed.
+func (r *TokenReconciler) clearStagedRenewal(ctx context.Context, token *tokenrenewerv1.Token) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stagingSecretName(token),
+			Namespace: token.Namespace,
+		},
+	}
+	if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("unable to delete staging secret: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+)
+
+// TestStagedRenewal tests the round trip of a renewal through its staging Secret
+func TestStagedRenewal(t *testing.T) {
+	staged := &stagedRenewal{
+		Token:               "s3cr3t",
+		Metadata:            tokenrenewerv1.ProviderMetadata{Metadata: "new", MetadataFields: map[string]string{"id": "2"}},
+		PreviousMetadata:    tokenrenewerv1.ProviderMetadata{Metadata: "old"},
+		PreviousFingerprint: fingerprint("old-token"),
+		PreviousRetained:    true,
+		ExpirationTime:      metav1.NewTime(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)),
+		IssueTime:           metav1.NewTime(time.Date(2029, 1, 2, 3, 4, 5, 0, time.UTC)),
+		RenewRequest:        "2029-01-02T03:00:00Z",
+	}
+
+	data, err := encodeStagedRenewal(staged)
+	if err != nil {
+		t.Fatalf("encodeStagedRenewal() error = %v", err)
+	}
+	if string(data[stagingKeyToken]) != "s3cr3t" {
+		t.Errorf("token entry = %q, want %q", data[stagingKeyToken], "s3cr3t")
+	}
+
+	got, err := decodeStagedRenewal(data)
+	if err != nil {
+		t.Fatalf("decodeStagedRenewal() error = %v", err)
+	}
+	if !got.ExpirationTime.Equal(&staged.ExpirationTime) || !got.IssueTime.Equal(&staged.IssueTime) {
+		t.Errorf("decodeStagedRenewal() times = %v/%v, want %v/%v", got.ExpirationTime, got.IssueTime, staged.ExpirationTime, staged.IssueTime)
+	}
+	got.ExpirationTime, got.IssueTime = staged.ExpirationTime, staged.IssueTime
+	if !reflect.DeepEqual(got, staged) {
+		t.Errorf("decodeStagedRenewal() = %+v, want %+v", got, staged)
+	}
+
+	for name, data := range map[string]map[string][]byte{
+		"missing_token":   {stagingKeyRenewal: data[stagingKeyRenewal]},
+		"missing_renewal": {stagingKeyToken: []byte("s3cr3t")},
+	} {
+		if _, err := decodeStagedRenewal(data); err == nil {
+			t.Errorf("decodeStagedRenewal(%s) error = nil, want error", name)
+		}
+	}
+}
@@ -18,10 +18,19 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
+	"sort"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -30,11 +39,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	tokenrenewerv1beta1 "github.com/guilhem/token-renewer/api/v1beta1"
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+	"github.com/guilhem/token-renewer/internal/metrics"
 	"github.com/guilhem/token-renewer/internal/providers"
+	"github.com/guilhem/token-renewer/shared"
+	"github.com/guilhem/token-renewer/shared/tracing"
 )
 
 // TokenReconciler reconciles a Token object
@@ -44,47 +57,158 @@ type TokenReconciler struct {
 	Recorder record.EventRecorder
 
 	ProvidersManager *providers.ProvidersManager
+
+	// DefaultCheckInterval is the validity check interval of the Tokens
+	// without spec.renewal.checkInterval. Zero disables the checks.
+	DefaultCheckInterval time.Duration
 }
 
 // +kubebuilder:rbac:groups=token-renewer.barpilot.io,resources=tokens,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=token-renewer.barpilot.io,resources=tokens/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=token-renewer.barpilot.io,resources=tokens/finalizers,verbs=update
+// +kubebuilder:rbac:groups=token-renewer.barpilot.io,resources=tokensecretgrants,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch;patch
+
+// Reasons used for both events and status conditions.
+const (
+	reasonSecretNotFound       = "SecretNotFound"
+	reasonSecretNotGranted     = "SecretReferenceNotGranted"
+	reasonSecretBootstrapError = "SecretBootstrapError"
+	reasonCredentialsNotFound  = "CredentialsNotFound"
+	reasonTokenInvalid         = "TokenInvalid"
+	reasonTokenKeyNotFound     = "TokenKeyNotFound"
+	reasonTokenEmpty           = "TokenEmpty"
+	reasonProviderNotFound     = "ProviderNotFound"
+	reasonTokenValidityError   = "TokenValidityError"
+	reasonTokenUpdateError     = "TokenUpdateError"
+	reasonTokenRenewalError    = "TokenRenewalError"
+	reasonSecretUpdateError    = "SecretUpdateError"
+	reasonTemplateRenderError  = "TemplateRenderError"
+	reasonInvalidRenewalPolicy = "InvalidRenewalPolicy"
+	reasonRevokePending        = "RevokePending"
+	reasonRevokeError          = "RevokeError"
+	reasonRevokeSkipped        = "RevokeSkipped"
+	reasonTokenRevoked         = "TokenRevoked"
+	reasonPreviousRevokeError  = "PreviousTokenRevokeError"
+	reasonStagingError         = "StagingError"
+	reasonRolloutError         = "RolloutError"
+	reasonRenewalDiscarded     = "StagedRenewalDiscarded"
+
+	reasonTokenValid          = "TokenValid"
+	reasonTokenRenewed        = "TokenRenewed"
+	reasonSecretBootstrapped  = "SecretBootstrapped"
+	reasonPreviousRevoked     = "PreviousTokenRevoked"
+	reasonSecretChanged       = "SecretChanged"
+	reasonExpirationShortened = "ExpirationShortened"
+	reasonTokenExpired        = "TokenExpired"
+	reasonTokenExpiring       = "TokenExpiring"
+	reasonSuspended           = "Suspended"
+	reasonNotSuspended        = "NotSuspended"
+	reasonExpirationUnknown   = "ExpirationUnknown"
+	reasonRenewalInProgress   = "RenewalInProgress"
+	reasonRenewalScheduled    = "RenewalScheduled"
+	reasonRenewalDeferred     = "RenewalDeferred"
+	reasonRenewalForced       = "RenewalForced"
+	reasonRenewalRequested    = "RenewalRequested"
+	reasonRenewalResumed      = "RenewalResumed"
+	reasonRolloutTriggered    = "RolloutTriggered"
+	reasonReconcileSucceeded  = "ReconcileSucceeded"
+)
+
+// minRequeueAfter keeps a schedule already in the past from requeueing the
+// Token in a tight loop.
+const minRequeueAfter = 30 * time.Second
 
 func (r *TokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracing.Start(ctx, "Reconcile Token", trace.WithAttributes(
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("token", req.Name),
+	))
+	result, err := r.reconcile(ctx, req)
+	tracing.End(span, err)
+	return result, err
+}
+
+// reconcile brings the Token, its Secret and its provider token to the desired
+// state. Each phase runs in its own span of the Reconcile span.
+func (r *TokenReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
 	log.Info("Reconciling Token")
 
 	// Fetch the Token instance
-	token := &tokenrenewerv1beta1.Token{}
+	token := &tokenrenewerv1.Token{}
 	if err := r.Get(ctx, req.NamespacedName, token); err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.TokenExpiration.Delete(req.NamespacedName)
+		}
 		log.Error(err, "unable to fetch Token")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !token.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, token)
+	}
+
+	if err := r.syncFinalizer(ctx, token, token.Spec.DeletionPolicy == tokenrenewerv1.DeletionPolicyRevoke); err != nil {
+		log.Error(err, "unable to update Token finalizers", "token", token.GetName())
+		return ctrl.Result{}, fmt.Errorf("unable to update token finalizers: %w", err)
+	}
+
+	if token.Spec.Suspend {
+		return r.reconcileSuspended(ctx, token)
+	}
+
 	// Get the secret reference
 	secretRef := token.Spec.SecretRef
+	secretNamespace := token.SecretNamespace()
+	if granted, err := r.secretReferenceGranted(ctx, token); err != nil {
+		return ctrl.Result{}, err
+	} else if !granted {
+		log.Info("Secret reference is not granted", "secret", secretRef.Name, "namespace", secretNamespace)
+		return r.fail(ctx, token, reasonSecretNotGranted, "Secret reference not granted",
+			fmt.Errorf("no TokenSecretGrant in namespace %s allows this Token to reference secret %s", secretNamespace, secretRef.Name))
+	}
+
 	secret := &corev1.Secret{}
-	if err := r.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: secretRef.Name}, secret); err != nil {
-		log.Error(err, "unable to fetch Secret", "secret", secretRef.Name)
-		r.Recorder.Event(token, "Warning", "SecretNotFound", "Secret not found")
-		return ctrl.Result{}, fmt.Errorf("unable to fetch secret: %w", err)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: secretNamespace, Name: secretRef.Name}, secret); err != nil {
+		if !apierrors.IsNotFound(err) || token.Spec.BootstrapSecretRef == nil {
+			log.Error(err, "unable to fetch Secret", "secret", secretRef.Name, "namespace", secretNamespace)
+			return r.fail(ctx, token, reasonSecretNotFound, "Secret not found", fmt.Errorf("unable to fetch secret: %w", err))
+		}
+
+		if err := r.bootstrapSecret(ctx, token, secret); err != nil {
+			log.Error(err, "unable to bootstrap Secret", "secret", secretRef.Name, "namespace", secretNamespace)
+			return r.fail(ctx, token, reasonSecretBootstrapError, "Error creating secret from bootstrap secret", err)
+		}
+		log.Info("Secret created from bootstrap secret", "secret", secretRef.Name, "bootstrapSecret", token.Spec.BootstrapSecretRef.Name)
+		r.Recorder.Eventf(token, "Normal", reasonSecretBootstrapped, "Secret %s created from bootstrap secret %s", secretRef.Name, token.Spec.BootstrapSecretRef.Name)
+	}
+
+	// A token renewed by the provider but never committed would otherwise be lost
+	if staged, err := r.stagedRenewal(ctx, token); err != nil {
+		log.Error(err, "unable to read staged renewal", "token", token.GetName())
+		return r.fail(ctx, token, reasonStagingError, "Error reading staged renewal", err)
+	} else if staged != nil {
+		if reason, message, err := r.resumeRenewal(ctx, token, secret, staged); err != nil {
+			metrics.RenewalFailures.WithLabelValues(token.Spec.Provider.Name, reason).Inc()
+			return r.fail(ctx, token, reason, message, err)
+		}
 	}
 
-	tokenBytes, exists := secret.Data["token"]
+	secretKey := secretRef.TokenKey()
+	tokenBytes, exists := secret.Data[secretKey]
 	if !exists {
-		log.Error(nil, "token key not found in secret", "secret", secretRef.Name, "key", "token")
-		r.Recorder.Event(token, "Warning", "TokenKeyNotFound", "Secret missing 'token' key")
-		return ctrl.Result{}, fmt.Errorf("token key not found in secret")
+		log.Error(nil, "token key not found in secret", "secret", secretRef.Name, "key", secretKey)
+		return r.fail(ctx, token, reasonTokenKeyNotFound, fmt.Sprintf("Secret missing '%s' key", secretKey), fmt.Errorf("key %q not found in secret", secretKey))
 	}
 
 	tokenValue := string(tokenBytes)
 	if tokenValue == "" {
 		log.Info("Token is empty, cannot use for renewal", "token", token.GetName())
-		r.Recorder.Event(token, "Warning", "TokenEmpty", "Token is empty")
-		return ctrl.Result{}, fmt.Errorf("token is empty")
+		return r.fail(ctx, token, reasonTokenEmpty, "Token is empty", fmt.Errorf("token is empty"))
 	}
 
 	// Get the provider for the token
@@ -92,27 +216,83 @@ func (r *TokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	provider, err := r.ProvidersManager.GetProvider(providerName)
 	if err != nil {
 		log.Error(err, "unable to get provider", "provider", providerName)
-		r.Recorder.Event(token, "Warning", "ProviderNotFound", "Provider not found")
-		return ctrl.Result{}, fmt.Errorf("unable to get provider: %w", err)
+		return r.fail(ctx, token, reasonProviderNotFound, "Provider not found", fmt.Errorf("unable to get provider: %w", err))
+	}
+
+	credentials, err := r.credentials(ctx, token)
+	if err != nil {
+		log.Error(err, "unable to fetch credentials", "token", token.GetName())
+		return r.fail(ctx, token, reasonCredentialsNotFound, "Credentials secret not found", err)
 	}
 
-	if token.Status.ExpirationTime.IsZero() {
-		log.Info("Token has no expiration time, setting it")
+	// A failed revocation of a previous token does not block the renewal; it
+	// is retried with backoff once the reconciliation is done
+	revokeErr := r.revokePreviousTokens(ctx, token, provider, tokenValue, credentials, time.Now())
+	if revokeErr != nil {
+		log.Error(revokeErr, "unable to revoke previous token", "token", token.GetName())
+	}
+
+	// The expiration time is only valid for the token it was read for, and is
+	// read again periodically to detect tokens revoked or shortened out of band
+	tokenFingerprint := fingerprint(tokenValue)
+	changed := token.Status.TokenFingerprint != "" && token.Status.TokenFingerprint != tokenFingerprint
+	checkInterval := r.checkInterval(token)
+	checkDue := validityCheckDue(token, checkInterval, time.Now())
+	if token.Status.ExpirationTime.IsZero() || token.Status.TokenFingerprint != tokenFingerprint || checkDue {
+		periodic := false
+		switch {
+		case changed:
+			log.Info("Token changed out of band, reading its expiration time", "token", token.GetName())
+			r.Recorder.Event(token, "Normal", reasonSecretChanged, "Token changed out of band, expiration time reset")
+		case token.Status.ExpirationTime.IsZero():
+			log.Info("Token has no expiration time, setting it")
+		default:
+			log.Info("Checking token validity", "token", token.GetName(), "interval", checkInterval)
+			periodic = true
+		}
 
-		t, err := provider.GetTokenValidity(ctx, token.Spec.Metadata, tokenValue)
+		checkTime := metav1.Now()
+		t, err := provider.GetTokenValidity(ctx, providerMetadata(token), tokenValue, credentials)
 		if err != nil {
-			log.Error(err, "unable to get token validity", "token", token.Spec.Metadata)
-			r.Recorder.Event(token, "Warning", "TokenValidityError", "Error getting token validity")
-			return ctrl.Result{}, fmt.Errorf("unable to get token validity: %w", err)
+			log.Error(err, "unable to get token validity", "token", token.GetName())
+			err = fmt.Errorf("unable to get token validity: %w", err)
+			// Only the provider reporting the token invalid means it was revoked
+			// out of band; failing to answer leaves the Valid condition as is
+			if !periodic || !errors.Is(err, shared.ErrTokenInvalid) {
+				return r.fail(ctx, token, reasonTokenValidityError, "Error getting token validity", err)
+			}
+
+			// The check is retried with backoff until the provider validates
+			// the token again
+			if _, uerr := r.updateStatus(ctx, token, func() {
+				setValidCondition(token, metav1.ConditionFalse, reasonTokenInvalid, err.Error())
+			}); uerr != nil {
+				log.Error(uerr, "unable to update Token status", "token", token.GetName())
+			}
+			return r.fail(ctx, token, reasonTokenInvalid, "Provider no longer validates the token", err)
+		}
+
+		validReason, validMessage := reasonTokenValid, "Token validated by the provider"
+		if periodic && t.Before(token.Status.ExpirationTime.Time) {
+			log.Info("Token expiration shortened out of band", "token", token.GetName(), "expiration", t)
+			validReason = reasonExpirationShortened
+			validMessage = fmt.Sprintf("Provider reports the token expires at %s instead of %s",
+				t.UTC().Format(time.RFC3339), token.Status.ExpirationTime.UTC().Format(time.RFC3339))
+			r.Recorder.Event(token, "Warning", reasonExpirationShortened, validMessage)
 		}
 
-		if op, err := controllerutil.CreateOrPatch(ctx, r.Client, token, func() error {
+		if op, err := r.updateStatus(ctx, token, func() {
 			token.Status.ExpirationTime = metav1.NewTime(*t)
-			return nil
+			token.Status.TokenFingerprint = tokenFingerprint
+			token.Status.LastValidityCheckTime = &checkTime
+			if token.Status.IssueTime.IsZero() || changed {
+				// The issue time of a token created outside of the controller is unknown
+				token.Status.IssueTime = metav1.Now()
+			}
+			setValidCondition(token, metav1.ConditionTrue, validReason, validMessage)
 		}); err != nil {
 			log.Error(err, "unable to update Token", "token", token.GetName())
-			r.Recorder.Event(token, "Warning", "TokenUpdateError", "Error updating token")
-			return ctrl.Result{}, fmt.Errorf("unable to update token: %w", err)
+			return r.fail(ctx, token, reasonTokenUpdateError, "Error updating token", fmt.Errorf("unable to update token: %w", err))
 		} else if op != controllerutil.OperationResultNone {
 			log.Info("Token updated successfully", "operation", op)
 			r.Recorder.Event(token, "Normal", "TokenUpdated", "Token updated successfully")
@@ -120,57 +300,1012 @@ func (r *TokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 
 	// Check if the token is about to expire
-	timeToUpdate := time.Now().Add(token.Spec.Renewval.BeforeDuration.Duration)
+	renewAt, _, forced, err := nextRenewal(token, time.Now())
+	if err != nil {
+		log.Error(err, "invalid renewal policy", "token", token.GetName())
+		return r.fail(ctx, token, reasonInvalidRenewalPolicy, "Invalid renewal policy", err)
+	}
+
+	readyReason, readyMessage := reasonTokenValid, "Token is valid"
+
+	// A new renew-requested-at annotation value forces a one-shot renewal
+	renewRequest := token.Annotations[tokenrenewerv1.AnnotationRenewRequestedAt]
+	requested := renewRequest != "" && renewRequest != token.Status.LastHandledRenewRequest
+
+	if requested || (!token.Status.ExpirationTime.IsZero() && !renewAt.After(time.Now())) {
+		renewingReason, renewingMessage := reasonRenewalInProgress, "Token is inside its renewal window"
+
+		if requested {
+			log.Info("Renewal requested by annotation", "token", token.GetName(), "requestedAt", renewRequest)
+			r.Recorder.Eventf(token, "Normal", reasonRenewalRequested, "Renewal requested at %s", renewRequest)
+			renewingReason, renewingMessage = reasonRenewalRequested, fmt.Sprintf("Renewal requested at %s", renewRequest)
+		} else {
+			log.Info("Token is about to expire, renewing", "token", token.GetName())
+		}
+
+		if forced && !requested {
+			log.Info("Token expires before the next maintenance window, renewing outside of it", "token", token.GetName())
+			r.Recorder.Eventf(token, "Warning", reasonRenewalForced,
+				"Token expires at %s before the next maintenance window, renewing outside of the window",
+				token.Status.ExpirationTime.UTC().Format(time.RFC3339))
+		}
 
-	if !token.Status.ExpirationTime.IsZero() && !token.Status.ExpirationTime.After(timeToUpdate) {
-		log.Info("Token is about to expire, renewing", "token", token.GetName())
-		newToken, newMeta, newTime, err := provider.RenewToken(ctx, token.Spec.Metadata, tokenValue)
+		idempotencyKey := renewalIdempotencyKey(token)
+		if _, err := r.updateStatus(ctx, token, func() {
+			token.Status.RenewalIdempotencyKey = idempotencyKey
+			meta.SetStatusCondition(&token.Status.Conditions, metav1.Condition{
+				Type:    tokenrenewerv1.ConditionRenewing,
+				Status:  metav1.ConditionTrue,
+				Reason:  renewingReason,
+				Message: renewingMessage,
+			})
+		}); err != nil {
+			log.Error(err, "unable to update Token status", "token", token.GetName())
+		}
+
+		previousMeta := providerMetadata(token)
+		issueTime := metav1.Now()
+		overlap := token.Spec.Renewal.Overlap.Duration
+		metrics.RenewalAttempts.WithLabelValues(providerName, renewingReason).Inc()
+		renewed, err := provider.RenewToken(ctx, shared.RenewRequest{
+			Metadata:       previousMeta,
+			Token:          tokenValue,
+			Credentials:    credentials,
+			RetainPrevious: overlap > 0,
+			IdempotencyKey: idempotencyKey,
+		})
 		if err != nil {
-			log.Error(err, "unable to renew token", "token", token.Spec.Metadata)
-			r.Recorder.Event(token, "Warning", "TokenRenewalError", "Error renewing token")
-			return ctrl.Result{}, fmt.Errorf("unable to renew token: %w", err)
+			log.Error(err, "unable to renew token", "token", token.GetName())
+			err = fmt.Errorf("unable to renew token: %w", err)
+			r.recordRenewal(ctx, token, tokenrenewerv1.RenewalRecord{
+				Time:             issueTime,
+				Outcome:          tokenrenewerv1.RenewalFailed,
+				PreviousMetadata: historyMetadata(previousMeta),
+				Message:          err.Error(),
+			})
+			metrics.RenewalFailures.WithLabelValues(providerName, reasonTokenRenewalError).Inc()
+			return r.fail(ctx, token, reasonTokenRenewalError, "Error renewing token", err)
 		}
 
 		log.Info("Token renewed successfully")
+		if overlap > 0 && !renewed.PreviousRetained {
+			log.Info("Provider revoked the previous token during the renewal, no overlap", "provider", providerName)
+		}
 
-		// Update the secret with the new token
-		if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
-			secret.StringData = make(map[string]string)
-			secret.StringData["token"] = newToken
-			return nil
-		}); err != nil {
-			r.Recorder.Event(token, "Warning", "SecretUpdateError", "Error updating secret")
-			return ctrl.Result{}, fmt.Errorf("unable to update secret: %w", err)
+		staged := &stagedRenewal{
+			Token:               renewed.Token,
+			Metadata:            tokenrenewerv1.ProviderMetadata{Metadata: renewed.Metadata.Value, MetadataFields: renewed.Metadata.Fields},
+			PreviousMetadata:    tokenrenewerv1.ProviderMetadata{Metadata: previousMeta.Value, MetadataFields: previousMeta.Fields},
+			PreviousFingerprint: fingerprint(tokenValue),
+			PreviousRetained:    renewed.PreviousRetained,
+			ExpirationTime:      metav1.NewTime(*renewed.Expiration),
+			IssueTime:           issueTime,
+		}
+		if requested {
+			staged.RenewRequest = renewRequest
+		}
+
+		// The new token only exists in memory until the Secret is updated, so
+		// it is written ahead to be recovered if the commit is interrupted
+		if err := r.stageRenewal(ctx, token, staged); err != nil {
+			log.Error(err, "unable to stage renewed token", "token", token.GetName())
+			r.Recorder.Event(token, "Warning", reasonStagingError, "Error staging renewed token, committing it directly")
+		}
+
+		if reason, message, err := r.commitRenewal(ctx, token, secret, staged); err != nil {
+			metrics.RenewalFailures.WithLabelValues(providerName, reason).Inc()
+			return r.fail(ctx, token, reason, message, err)
+		}
+		metrics.RenewalSuccesses.WithLabelValues(providerName, renewingReason).Inc()
+
+		readyReason, readyMessage = reasonTokenRenewed, "Token renewed successfully"
+		if requested {
+			readyMessage = fmt.Sprintf("Token renewed on request at %s", renewRequest)
+		}
+	} else {
+		currentMeta := providerMetadata(token)
+		rendered, renderErr := renderSecretTemplates(token.Spec.Template, secretValues{
+			Token:          tokenValue,
+			Metadata:       currentMeta.Value,
+			MetadataFields: currentMeta.Fields,
+			Expiration:     token.Status.ExpirationTime.Time,
+		})
+
+		// Keep the additional keys and templates in sync with the current token
+		if op, err := r.syncSecret(ctx, token, secret, tokenValue, rendered); err != nil {
+			return r.fail(ctx, token, reasonSecretUpdateError, "Error updating secret", fmt.Errorf("unable to update secret: %w", err))
 		} else if op != controllerutil.OperationResultNone {
 			r.Recorder.Event(token, "Normal", "SecretUpdated", "Secret updated successfully")
 		}
 
-		// Update the token with the new metadata and expiration time
-		if op, err := controllerutil.CreateOrPatch(ctx, r.Client, token, func() error {
-			token.Spec.Metadata = newMeta
-			token.Status.ExpirationTime = metav1.NewTime(*newTime)
-			return nil
-		}); err != nil {
-			r.Recorder.Event(token, "Warning", "TokenUpdateError", "Error updating token")
-			return ctrl.Result{}, fmt.Errorf("unable to update token: %w", err)
-		} else if op != controllerutil.OperationResultNone {
-			log.Info("Token updated successfully", "operation", op)
-			r.Recorder.Event(token, "Normal", "TokenUpdated", "Token updated successfully")
+		if renderErr != nil {
+			log.Error(renderErr, "unable to render secret template", "token", token.GetName())
+			return r.fail(ctx, token, reasonTemplateRenderError, "Error rendering secret template", fmt.Errorf("unable to render secret template: %w", renderErr))
+		}
+	}
+
+	// Restart the workloads still running with a previous token. Failed
+	// restarts do not fail the reconciliation; they are retried with backoff
+	rollouts, rolloutErr := r.rolloutWorkloads(ctx, token)
+	if rolloutErr != nil {
+		log.Error(rolloutErr, "unable to roll out workloads", "token", token.GetName())
+	}
+
+	renewAt, deferred, _, err := nextRenewal(token, time.Now())
+	if err != nil {
+		return r.fail(ctx, token, reasonInvalidRenewalPolicy, "Invalid renewal policy", err)
+	}
+	expirationTime, issueTime := token.Status.ExpirationTime, token.Status.IssueTime
+
+	renewingStatus, renewingReason := metav1.ConditionFalse, reasonRenewalScheduled
+	renewingMessage := fmt.Sprintf("Next renewal at %s", renewAt.UTC().Format(time.RFC3339))
+	if deferred {
+		// The token is inside its renewal window but waits for a maintenance window
+		log.Info("Renewal deferred to the next maintenance window", "token", token.GetName(), "window", renewAt)
+		renewingStatus, renewingReason = metav1.ConditionTrue, reasonRenewalDeferred
+		renewingMessage = fmt.Sprintf("Renewal deferred to the maintenance window opening at %s", renewAt.UTC().Format(time.RFC3339))
+	}
+
+	// Record the successful reconciliation in the status
+	if _, err := r.updateStatus(ctx, token, func() {
+		token.Status.ExpirationTime = expirationTime
+		token.Status.IssueTime = issueTime
+		token.Status.Rollouts = rollouts
+		meta.SetStatusCondition(&token.Status.Conditions, metav1.Condition{
+			Type:    tokenrenewerv1.ConditionReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  readyReason,
+			Message: readyMessage,
+		})
+		meta.SetStatusCondition(&token.Status.Conditions, metav1.Condition{
+			Type:    tokenrenewerv1.ConditionDegraded,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonReconcileSucceeded,
+			Message: "Reconciliation succeeded",
+		})
+		meta.SetStatusCondition(&token.Status.Conditions, metav1.Condition{
+			Type:    tokenrenewerv1.ConditionRenewing,
+			Status:  renewingStatus,
+			Reason:  renewingReason,
+			Message: renewingMessage,
+		})
+	}); err != nil {
+		log.Error(err, "unable to update Token status", "token", token.GetName())
+		r.Recorder.Event(token, "Warning", reasonTokenUpdateError, "Error updating token")
+		return ctrl.Result{}, fmt.Errorf("unable to update token status: %w", err)
+	}
+
+	if err := errors.Join(revokeErr, rolloutErr); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Come back for the next validity check or at the end of the next overlap
+	// when they come before the renewal
+	requeueAt := renewAt
+	if checkInterval > 0 && token.Status.LastValidityCheckTime != nil {
+		if next := token.Status.LastValidityCheckTime.Add(checkInterval); next.Before(requeueAt) {
+			requeueAt = next
+		}
+	}
+	for _, pending := range token.Status.PendingRevocations {
+		if pending.RevokeAfter.Time.Before(requeueAt) {
+			requeueAt = pending.RevokeAfter.Time
 		}
 	}
 
 	return ctrl.Result{
-		RequeueAfter: time.Until(token.Status.ExpirationTime.Add(-token.Spec.Renewval.BeforeDuration.Duration)),
+		RequeueAfter: max(time.Until(requeueAt), minRequeueAfter),
 	}, nil
 }
 
+// commitRenewal writes a renewed token to the target Secret, records it in the
+// Token status and drops its staging Secret. On failure it returns the reason
+// and message to report, and the staged renewal is committed again by the next
+// reconciliation.
+func (r *TokenReconciler) commitRenewal(ctx context.Context, token *tokenrenewerv1.Token, secret *corev1.Secret, staged *stagedRenewal) (_, _ string, err error) {
+	ctx, span := tracing.Start(ctx, "CommitRenewal")
+	defer func() { tracing.End(span, err) }()
+
+	log := logf.FromContext(ctx)
+
+	newMeta := staged.metadata()
+	previousMeta := staged.previousMetadata()
+	issueTime := staged.IssueTime
+
+	rendered, renderErr := renderSecretTemplates(token.Spec.Template, secretValues{
+		Token:          staged.Token,
+		Metadata:       newMeta.Value,
+		MetadataFields: newMeta.Fields,
+		Expiration:     staged.ExpirationTime.Time,
+	})
+
+	record := tokenrenewerv1.RenewalRecord{
+		Time:             issueTime,
+		Outcome:          tokenrenewerv1.RenewalSucceeded,
+		PreviousMetadata: historyMetadata(previousMeta),
+		ExpirationTime:   &metav1.Time{Time: staged.ExpirationTime.Time},
+		Fingerprint:      fingerprint(staged.Token),
+	}
+
+	// Update the secret with the new token
+	if op, err := r.syncSecret(ctx, token, secret, staged.Token, rendered); err != nil {
+		err = fmt.Errorf("unable to update secret: %w", err)
+		record.Outcome, record.Message = tokenrenewerv1.RenewalFailed, err.Error()
+		record.Metadata = historyMetadata(newMeta)
+		r.recordRenewal(ctx, token, record)
+		return reasonSecretUpdateError, "Error updating secret", err
+	} else if op != controllerutil.OperationResultNone {
+		r.Recorder.Event(token, "Normal", "SecretUpdated", "Secret updated successfully")
+	}
+
+	// Update the token with the new metadata and expiration time
+	if op, err := r.updateStatus(ctx, token, func() {
+		setProviderMetadata(token, newMeta)
+		token.Status.ExpirationTime = staged.ExpirationTime
+		token.Status.IssueTime = issueTime
+		token.Status.LastRenewalTime = &issueTime
+		token.Status.TokenFingerprint = record.Fingerprint
+		token.Status.LastValidityCheckTime = &issueTime
+		token.Status.RotationGeneration++
+		token.Status.RenewalIdempotencyKey = ""
+		setValidCondition(token, metav1.ConditionTrue, reasonTokenRenewed, "Token renewed by the provider")
+		record.Metadata = historyMetadata(providerMetadata(token))
+		appendHistory(token, record)
+		if staged.PreviousRetained {
+			token.Status.PendingRevocations = append(token.Status.PendingRevocations, tokenrenewerv1.PendingRevocation{
+				Metadata: tokenrenewerv1.ProviderMetadata{
+					Metadata:       previousMeta.Value,
+					MetadataFields: previousMeta.Fields,
+				},
+				Fingerprint: staged.PreviousFingerprint,
+				RevokeAfter: metav1.NewTime(issueTime.Add(token.Spec.Renewal.Overlap.Duration)),
+			})
+		}
+		if staged.RenewRequest != "" {
+			token.Status.LastHandledRenewRequest = staged.RenewRequest
+		}
+	}); err != nil {
+		return reasonTokenUpdateError, "Error updating token", fmt.Errorf("unable to update token: %w", err)
+	} else if op != controllerutil.OperationResultNone {
+		log.Info("Token updated successfully", "operation", op)
+		r.Recorder.Event(token, "Normal", "TokenUpdated", "Token updated successfully")
+	}
+
+	// A staging Secret left behind is recognized as committed on the next
+	// reconciliation, so failing to delete it is not an error
+	if err := r.clearStagedRenewal(ctx, token); err != nil {
+		log.Error(err, "unable to delete staging secret", "token", token.GetName())
+	}
+
+	if renderErr != nil {
+		log.Error(renderErr, "unable to render secret template", "token", token.GetName())
+		return reasonTemplateRenderError, "Error rendering secret template", fmt.Errorf("unable to render secret template: %w", renderErr)
+	}
+	return "", "", nil
+}
+
+// resumeRenewal commits a renewal left in the staging Secret by an interrupted
+// reconciliation. A renewal already committed only has its staging Secret
+// deleted, as does a renewal whose Secret was replaced out of band since: the
+// newer value is kept over the staged token.
+func (r *TokenReconciler) resumeRenewal(ctx context.Context, token *tokenrenewerv1.Token, secret *corev1.Secret, staged *stagedRenewal) (_, _ string, err error) {
+	ctx, span := tracing.Start(ctx, "ResumeRenewal")
+	defer func() { tracing.End(span, err) }()
+
+	log := logf.FromContext(ctx)
+
+	if token.Status.TokenFingerprint == fingerprint(staged.Token) &&
+		string(secret.Data[token.Spec.SecretRef.TokenKey()]) == staged.Token {
+		if err := r.clearStagedRenewal(ctx, token); err != nil {
+			return reasonStagingError, "Error deleting staging secret", err
+		}
+		return "", "", nil
+	}
+
+	if current := string(secret.Data[token.Spec.SecretRef.TokenKey()]); current != staged.Token && fingerprint(current) != staged.PreviousFingerprint {
+		log.Info("Secret replaced since the renewal was staged, discarding it", "token", token.GetName(), "issueTime", staged.IssueTime)
+		r.Recorder.Event(token, "Warning", reasonRenewalDiscarded, "Secret replaced out of band since the renewal was staged, staged token discarded")
+		if err := r.clearStagedRenewal(ctx, token); err != nil {
+			return reasonStagingError, "Error deleting staging secret", err
+		}
+		return "", "", nil
+	}
+
+	log.Info("Resuming interrupted renewal", "token", token.GetName(), "issueTime", staged.IssueTime)
+	r.Recorder.Event(token, "Normal", reasonRenewalResumed, "Committing a renewed token staged by an interrupted renewal")
+	if reason, message, err := r.commitRenewal(ctx, token, secret, staged); err != nil {
+		return reason, message, err
+	}
+	metrics.RenewalSuccesses.WithLabelValues(token.Spec.Provider.Name, reasonRenewalResumed).Inc()
+	return "", "", nil
+}
+
+// renewalIdempotencyKey returns the idempotency key of the next renewal of the
+// Token. It only changes once a renewal is committed, so retries after a
+// failure reuse it.
+func renewalIdempotencyKey(token *tokenrenewerv1.Token) string {
+	return fmt.Sprintf("%s/%d", token.UID, token.Status.RotationGeneration+1)
+}
+
+// checkInterval returns the validity check interval of the Token, zero when
+// the checks are disabled.
+func (r *TokenReconciler) checkInterval(token *tokenrenewerv1.Token) time.Duration {
+	if interval := token.Spec.Renewal.CheckInterval; interval != nil {
+		return interval.Duration
+	}
+	return r.DefaultCheckInterval
+}
+
+// validityCheckDue reports whether the validity of a token with a known
+// expiration time must be checked again.
+func validityCheckDue(token *tokenrenewerv1.Token, interval time.Duration, now time.Time) bool {
+	if interval <= 0 || token.Status.ExpirationTime.IsZero() {
+		return false
+	}
+	last := token.Status.LastValidityCheckTime
+	return last == nil || !last.Add(interval).After(now)
+}
+
+// revokePreviousTokens revokes the previous tokens whose overlap has ended.
+// A previous token failing to be revoked stays pending.
+func (r *TokenReconciler) revokePreviousTokens(ctx context.Context, token *tokenrenewerv1.Token, provider shared.TokenProvider, tokenValue string, credentials shared.Credentials, now time.Time) (err error) {
+	ctx, span := tracing.Start(ctx, "RevokePreviousTokens")
+	defer func() { tracing.End(span, err) }()
+
+	log := logf.FromContext(ctx)
+
+	var pending []tokenrenewerv1.PendingRevocation
+	var errs []error
+	for _, previous := range token.Status.PendingRevocations {
+		if previous.RevokeAfter.After(now) {
+			pending = append(pending, previous)
+			continue
+		}
+
+		if err := provider.RevokePreviousToken(ctx, previousMetadata(previous), tokenValue, credentials); err != nil {
+			r.Recorder.Eventf(token, "Warning", reasonPreviousRevokeError, "Error revoking previous token %s: %v", previous.Fingerprint, err)
+			pending = append(pending, previous)
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Info("Previous token revoked", "token", token.GetName(), "fingerprint", previous.Fingerprint)
+		r.Recorder.Eventf(token, "Normal", reasonPreviousRevoked, "Previous token %s revoked", previous.Fingerprint)
+	}
+
+	if len(pending) != len(token.Status.PendingRevocations) {
+		if _, err := r.updateStatus(ctx, token, func() {
+			token.Status.PendingRevocations = pending
+		}); err != nil {
+			return fmt.Errorf("unable to update token status: %w", err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("unable to revoke previous token: %w", err)
+	}
+	return nil
+}
+
+// previousMetadata returns the provider metadata of a previous token.
+func previousMetadata(previous tokenrenewerv1.PendingRevocation) shared.Metadata {
+	return shared.Metadata{
+		Value:  previous.Metadata.Metadata,
+		Fields: maps.Clone(previous.Metadata.MetadataFields),
+	}
+}
+
+// secretReferenceGranted reports whether the Token may use its Secret.
+func (r *TokenReconciler) secretReferenceGranted(ctx context.Context, token *tokenrenewerv1.Token) (bool, error) {
+	if token.SecretNamespace() == token.Namespace {
+		return true, nil
+	}
+
+	grants := &tokenrenewerv1.TokenSecretGrantList{}
+	if err := r.List(ctx, grants, client.InNamespace(token.SecretNamespace())); err != nil {
+		return false, fmt.Errorf("unable to list token secret grants: %w", err)
+	}
+	return tokenrenewerv1.SecretReferenceGranted(token, grants.Items), nil
+}
+
+// finalize revokes the provider token of a deleted Token with the Revoke
+// deletion policy, then releases the Token. While the provider is not
+// connected the deletion is held and retried with backoff; setting the policy
+// to Retain releases the Token without revoking. The token cannot be revoked
+// without its Secret, so a missing Secret releases the Token with a warning.
+func (r *TokenReconciler) finalize(ctx context.Context, token *tokenrenewerv1.Token) (_ ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "Finalize")
+	defer func() { tracing.End(span, err) }()
+
+	log := logf.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(token, tokenrenewerv1.FinalizerRevoke) {
+		return ctrl.Result{}, nil
+	}
+
+	if token.Spec.DeletionPolicy == tokenrenewerv1.DeletionPolicyRevoke {
+		tokenValue, err := r.revocableToken(ctx, token)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// A renewal interrupted before its commit left the current token staged
+		currentMeta := providerMetadata(token)
+		if tokenValue != "" {
+			staged, err := r.stagedRenewal(ctx, token)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if staged != nil {
+				tokenValue, currentMeta = staged.Token, staged.metadata()
+			}
+		}
+
+		if tokenValue != "" {
+			providerName := token.Spec.Provider.Name
+			provider, err := r.ProvidersManager.GetProvider(providerName)
+			if err != nil {
+				log.Info("Provider is not connected, holding the Token deletion", "provider", providerName)
+				return r.fail(ctx, token, reasonRevokePending, "Provider not connected, token not revoked yet",
+					fmt.Errorf("provider %q is not connected; set spec.deletionPolicy to Retain to delete the Token without revoking it: %w", providerName, err))
+			}
+
+			credentials, err := r.credentials(ctx, token)
+			if apierrors.IsNotFound(err) {
+				r.Recorder.Eventf(token, "Warning", reasonRevokeSkipped, "Credentials secret %s not found, provider token not revoked", token.Spec.CredentialsSecretRef.Name)
+			} else if err != nil {
+				return ctrl.Result{}, err
+			} else {
+				// The previous tokens of a two-phase rotation would outlive the Token
+				for _, previous := range token.Status.PendingRevocations {
+					if err := provider.RevokePreviousToken(ctx, previousMetadata(previous), tokenValue, credentials); err != nil {
+						log.Error(err, "unable to revoke previous token", "token", token.GetName())
+						return r.fail(ctx, token, reasonRevokeError, "Error revoking previous token", fmt.Errorf("unable to revoke previous token: %w", err))
+					}
+				}
+
+				if err := provider.RevokeToken(ctx, currentMeta, tokenValue, credentials); err != nil {
+					log.Error(err, "unable to revoke token", "token", token.GetName())
+					return r.fail(ctx, token, reasonRevokeError, "Error revoking token", fmt.Errorf("unable to revoke token: %w", err))
+				}
+
+				log.Info("Token revoked", "token", token.GetName())
+				r.Recorder.Event(token, "Normal", reasonTokenRevoked, "Provider token revoked")
+			}
+		}
+	}
+
+	if err := r.syncFinalizer(ctx, token, false); err != nil {
+		log.Error(err, "unable to remove Token finalizer", "token", token.GetName())
+		return ctrl.Result{}, fmt.Errorf("unable to remove token finalizer: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// revocableToken returns the token value to revoke, or an empty value when the
+// token must not or cannot be revoked, in which case a warning event explains why.
+func (r *TokenReconciler) revocableToken(ctx context.Context, token *tokenrenewerv1.Token) (string, error) {
+	if token.Spec.Suspend {
+		r.Recorder.Event(token, "Warning", reasonRevokeSkipped, "Token is suspended, provider token not revoked")
+		return "", nil
+	}
+
+	secretRef := token.Spec.SecretRef
+	if granted, err := r.secretReferenceGranted(ctx, token); err != nil {
+		return "", err
+	} else if !granted {
+		r.Recorder.Event(token, "Warning", reasonRevokeSkipped, "Secret reference not granted, provider token not revoked")
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: token.SecretNamespace(), Name: secretRef.Name}, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("unable to fetch secret: %w", err)
+		}
+		r.Recorder.Eventf(token, "Warning", reasonRevokeSkipped, "Secret %s not found, provider token not revoked", secretRef.Name)
+		return "", nil
+	}
+
+	tokenValue := string(secret.Data[secretRef.TokenKey()])
+	if tokenValue == "" {
+		r.Recorder.Eventf(token, "Warning", reasonRevokeSkipped, "Secret %s has no token, provider token not revoked", secretRef.Name)
+	}
+	return tokenValue, nil
+}
+
+// syncFinalizer adds or removes the revoke finalizer.
+func (r *TokenReconciler) syncFinalizer(ctx context.Context, token *tokenrenewerv1.Token, want bool) error {
+	patch := client.MergeFromWithOptions(token.DeepCopy(), client.MergeFromWithOptimisticLock{})
+
+	var changed bool
+	if want {
+		changed = controllerutil.AddFinalizer(token, tokenrenewerv1.FinalizerRevoke)
+	} else {
+		changed = controllerutil.RemoveFinalizer(token, tokenrenewerv1.FinalizerRevoke)
+	}
+	if !changed {
+		return nil
+	}
+	return r.Patch(ctx, token, patch)
+}
+
+// reconcileSuspended only reports the state of a suspended Token: the Secret
+// and the provider are left untouched and the Token is not requeued. A warning
+// event is still raised once the token is inside its renewal window.
+func (r *TokenReconciler) reconcileSuspended(ctx context.Context, token *tokenrenewerv1.Token) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Token is suspended, skipping validity checks and renewals")
+
+	if expiration := token.Status.ExpirationTime; !expiration.IsZero() {
+		renewAt, err := renewalTime(token)
+		if err != nil {
+			renewAt = expiration.Time
+		}
+
+		switch now := time.Now(); {
+		case !expiration.After(now):
+			r.Recorder.Eventf(token, "Warning", reasonTokenExpired,
+				"Token is suspended and expired at %s", expiration.UTC().Format(time.RFC3339))
+		case !renewAt.After(now):
+			r.Recorder.Eventf(token, "Warning", reasonTokenExpiring,
+				"Token is suspended and expires at %s", expiration.UTC().Format(time.RFC3339))
+		}
+	}
+
+	if _, err := r.updateStatus(ctx, token, func() {}); err != nil {
+		log.Error(err, "unable to update Token status", "token", token.GetName())
+		return ctrl.Result{}, fmt.Errorf("unable to update token status: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// renewalTime returns when the token should be renewed according to the
+// renewal policy. With renewAtFraction, the lifetime runs from the issue time to
+// the expiration time; an unknown lifetime falls back to the minRemaining floor.
+// A margin covering the whole known lifetime would renew the token as soon as
+// it is issued, so the renewal then waits for half of the lifetime.
+func renewalTime(token *tokenrenewerv1.Token) (time.Time, error) {
+	renewal := token.Spec.Renewal
+	expiration := token.Status.ExpirationTime.Time
+	issued := token.Status.IssueTime.Time
+	lifetimeKnown := !issued.IsZero() && issued.Before(expiration)
+
+	fraction, err := renewal.Fraction()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var renewAt time.Time
+	if fraction == 0 {
+		renewAt = expiration.Add(-renewal.BeforeDuration.Duration)
+	} else {
+		renewAt = expiration
+		if lifetimeKnown {
+			lifetime := expiration.Sub(issued)
+			renewAt = issued.Add(time.Duration(float64(lifetime) * fraction))
+		}
+		if floor := expiration.Add(-renewal.MinRemaining.Duration); floor.Before(renewAt) {
+			renewAt = floor
+		}
+	}
+
+	if lifetimeKnown && !renewAt.After(issued) {
+		renewAt = issued.Add(expiration.Sub(issued) / 2)
+	}
+
+	return renewAt, nil
+}
+
+// providerMetadata returns the metadata identifying the token on the provider
+// side. The metadata recorded in the status wins; the spec metadata is only a
+// seed used until the status has been populated or when the spec changes.
+func providerMetadata(token *tokenrenewerv1.Token) shared.Metadata {
+	if current := token.Status.CurrentMetadata; current != nil && current.SeedHash == metadataSeedHash(token) {
+		return shared.Metadata{
+			Value:  current.Metadata,
+			Fields: current.MetadataFields,
+		}
+	}
+
+	return shared.Metadata{
+		Value:  token.Spec.Metadata,
+		Fields: token.Spec.MetadataFields,
+	}
+}
+
+// setProviderMetadata stores the metadata returned by the provider after a
+// renewal in the status. Parts the provider did not return are left untouched.
+func setProviderMetadata(token *tokenrenewerv1.Token, metadata shared.Metadata) {
+	current := providerMetadata(token)
+	if metadata.Value != "" {
+		current.Value = metadata.Value
+	}
+	if len(metadata.Fields) > 0 {
+		current.Fields = metadata.Fields
+	}
+
+	token.Status.CurrentMetadata = &tokenrenewerv1.CurrentMetadata{
+		Metadata:       current.Value,
+		MetadataFields: current.Fields,
+		SeedHash:       metadataSeedHash(token),
+	}
+}
+
+// seedProviderMetadata populates status.currentMetadata from the spec for new
+// Tokens, for Tokens created before the metadata moved to the status, and when
+// the spec metadata has been changed.
+func seedProviderMetadata(token *tokenrenewerv1.Token) {
+	if current := token.Status.CurrentMetadata; current != nil && current.SeedHash == metadataSeedHash(token) {
+		return
+	}
+	setProviderMetadata(token, shared.Metadata{})
+}
+
+// metadataSeedHash returns a stable hash of the spec metadata.
+func metadataSeedHash(token *tokenrenewerv1.Token) string {
+	h := sha256.New()
+	h.Write([]byte(token.Spec.Metadata))
+
+	keys := make([]string, 0, len(token.Spec.MetadataFields))
+	for key := range token.Spec.MetadataFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "\x00%s=%s", key, token.Spec.MetadataFields[key])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// recordRenewal adds a renewal attempt to the status history.
+func (r *TokenReconciler) recordRenewal(ctx context.Context, token *tokenrenewerv1.Token, record tokenrenewerv1.RenewalRecord) {
+	if _, err := r.updateStatus(ctx, token, func() {
+		appendHistory(token, record)
+	}); err != nil {
+		logf.FromContext(ctx).Error(err, "unable to record renewal in Token history", "token", token.GetName())
+	}
+}
+
+// appendHistory prepends record to the status history and drops the records
+// beyond spec.historyLimit. A failure identical to the latest record only
+// refreshes its time, so that retries do not push older renewals out.
+func appendHistory(token *tokenrenewerv1.Token, record tokenrenewerv1.RenewalRecord) {
+	history := token.Status.History
+	if record.Outcome == tokenrenewerv1.RenewalFailed && len(history) > 0 &&
+		history[0].Outcome == tokenrenewerv1.RenewalFailed && history[0].Message == record.Message {
+		history[0].Time = record.Time
+		return
+	}
+
+	limit := token.Spec.HistoryLength()
+	history = append([]tokenrenewerv1.RenewalRecord{record}, history...)
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	if len(history) == 0 {
+		history = nil
+	}
+	token.Status.History = history
+}
+
+// historyMetadata converts provider metadata for the status history.
+func historyMetadata(metadata shared.Metadata) *tokenrenewerv1.ProviderMetadata {
+	if metadata.Value == "" && len(metadata.Fields) == 0 {
+		return nil
+	}
+	return &tokenrenewerv1.ProviderMetadata{
+		Metadata:       metadata.Value,
+		MetadataFields: maps.Clone(metadata.Fields),
+	}
+}
+
+// fingerprint identifies a token value without disclosing it.
+func fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
+
+// credentials returns the entries of the credentials Secret of the Token, or nil
+// when the Token has none. A missing Secret is reported as a NotFound error.
+func (r *TokenReconciler) credentials(ctx context.Context, token *tokenrenewerv1.Token) (_ shared.Credentials, err error) {
+	ctx, span := tracing.Start(ctx, "Credentials")
+	defer func() { tracing.End(span, err) }()
+
+	ref := token.Spec.CredentialsSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: token.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("unable to fetch credentials secret: %w", err)
+	}
+
+	credentials := make(shared.Credentials, len(secret.Data))
+	for k, v := range secret.Data {
+		credentials[k] = string(v)
+	}
+	return credentials, nil
+}
+
+// bootstrapSecret creates the missing target Secret with the token of the
+// bootstrap Secret. The new Secret is owned by the Token when both live in the
+// same namespace.
+func (r *TokenReconciler) bootstrapSecret(ctx context.Context, token *tokenrenewerv1.Token, secret *corev1.Secret) (err error) {
+	ctx, span := tracing.Start(ctx, "BootstrapSecret")
+	defer func() { tracing.End(span, err) }()
+
+	bootstrapRef := token.Spec.BootstrapSecretRef
+
+	bootstrap := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: token.Namespace, Name: bootstrapRef.Name}, bootstrap); err != nil {
+		return fmt.Errorf("unable to fetch bootstrap secret: %w", err)
+	}
+	value, ok := bootstrap.Data[bootstrapRef.TokenKey()]
+	if !ok || len(value) == 0 {
+		return fmt.Errorf("key %q not found in bootstrap secret %s", bootstrapRef.TokenKey(), bootstrapRef.Name)
+	}
+
+	*secret = corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      token.Spec.SecretRef.Name,
+			Namespace: token.SecretNamespace(),
+		},
+		Data: map[string][]byte{token.Spec.SecretRef.TokenKey(): value},
+	}
+	if token.Spec.Template != nil {
+		secret.Type = token.Spec.Template.Type
+	}
+	if secret.Namespace == token.Namespace {
+		if err := controllerutil.SetOwnerReference(token, secret, r.Scheme); err != nil {
+			return fmt.Errorf("unable to set owner reference: %w", err)
+		}
+	}
+
+	if err := r.Create(ctx, secret); err != nil {
+		return fmt.Errorf("unable to create secret: %w", err)
+	}
+	return nil
+}
+
+// syncSecret writes the token value under the configured key and every
+// additional key of the Secret, together with the rendered template entries.
+// When the template asks for another Secret type, the Secret is recreated since
+// the type of an existing Secret cannot be changed.
+func (r *TokenReconciler) syncSecret(ctx context.Context, token *tokenrenewerv1.Token, secret *corev1.Secret, value string, rendered map[string][]byte) (_ controllerutil.OperationResult, err error) {
+	ctx, span := tracing.Start(ctx, "SyncSecret")
+	defer func() { tracing.End(span, err) }()
+
+	var secretType corev1.SecretType
+	if token.Spec.Template != nil {
+		secretType = token.Spec.Template.Type
+	}
+
+	var previous *corev1.Secret
+	if secretType != "" && secret.Type != secretType {
+		previous = secret.DeepCopy()
+		if err := r.Delete(ctx, secret, client.Preconditions{UID: &secret.UID, ResourceVersion: &secret.ResourceVersion}); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("unable to delete secret to change its type: %w", err)
+		}
+		*secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            previous.Name,
+				Namespace:       previous.Namespace,
+				Labels:          previous.Labels,
+				Annotations:     previous.Annotations,
+				OwnerReferences: previous.OwnerReferences,
+			},
+			Data: previous.Data,
+		}
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.CreationTimestamp.IsZero() && secretType != "" {
+			secret.Type = secretType
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		for key, data := range rendered {
+			secret.Data[key] = data
+		}
+		if token.Spec.SecretRef.SetOwnerReference && secret.Namespace == token.Namespace {
+			if err := controllerutil.SetOwnerReference(token, secret, r.Scheme); err != nil {
+				return fmt.Errorf("unable to set owner reference: %w", err)
+			}
+		}
+		secret.Data[token.Spec.SecretRef.TokenKey()] = []byte(value)
+		for _, key := range token.Spec.SecretRef.AdditionalKeys {
+			secret.Data[key] = []byte(value)
+		}
+		return nil
+	})
+	if err != nil && previous != nil {
+		// Put the original Secret back so that the token is not lost.
+		restored := previous.DeepCopy()
+		restored.ResourceVersion = ""
+		restored.UID = ""
+		restored.CreationTimestamp = metav1.Time{}
+		if restored.Data == nil {
+			restored.Data = make(map[string][]byte)
+		}
+		restored.Data[token.Spec.SecretRef.TokenKey()] = []byte(value)
+		if rerr := r.Create(ctx, restored); rerr != nil {
+			return op, errors.Join(err, fmt.Errorf("unable to restore secret: %w", rerr))
+		}
+	}
+
+	return op, err
+}
+
+// fail records a failed reconciliation: it emits a warning event with the given
+// reason, reflects the error in the status conditions and returns the error so
+// that the request is retried with backoff.
+func (r *TokenReconciler) fail(ctx context.Context, token *tokenrenewerv1.Token, reason, message string, err error) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	r.Recorder.Event(token, "Warning", reason, message)
+
+	if _, uerr := r.updateStatus(ctx, token, func() {
+		meta.SetStatusCondition(&token.Status.Conditions, metav1.Condition{
+			Type:    tokenrenewerv1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: err.Error(),
+		})
+		meta.SetStatusCondition(&token.Status.Conditions, metav1.Condition{
+			Type:    tokenrenewerv1.ConditionDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: err.Error(),
+		})
+	}); uerr != nil {
+		log.Error(uerr, "unable to update Token status", "token", token.GetName())
+	}
+
+	return ctrl.Result{}, err
+}
+
+// updateStatus applies mutate to the Token status and patches it. The current
+// metadata seed, the observed generation and the Expired and Suspended
+// conditions are refreshed on every update, and the expiration time is
+// exported to the metrics once patched.
+func (r *TokenReconciler) updateStatus(ctx context.Context, token *tokenrenewerv1.Token, mutate func()) (_ controllerutil.OperationResult, err error) {
+	ctx, span := tracing.Start(ctx, "UpdateStatus")
+	defer func() { tracing.End(span, err) }()
+
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, token, func() error {
+		seedProviderMetadata(token)
+		mutate()
+		token.Status.ObservedGeneration = token.Generation
+		setExpiredCondition(token, time.Now())
+		setSuspendedCondition(token)
+		for i := range token.Status.Conditions {
+			token.Status.Conditions[i].ObservedGeneration = token.Generation
+		}
+		return nil
+	})
+	if err == nil && !token.Status.ExpirationTime.IsZero() {
+		metrics.TokenExpiration.Set(client.ObjectKeyFromObject(token), token.Spec.Provider.Name, token.Status.ExpirationTime.Time)
+	}
+	return op, err
+}
+
+// setExpiredCondition sets the Expired condition from the known expiration time.
+func setExpiredCondition(token *tokenrenewerv1.Token, now time.Time) {
+	condition := metav1.Condition{Type: tokenrenewerv1.ConditionExpired}
+
+	switch expiration := token.Status.ExpirationTime; {
+	case expiration.IsZero():
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = reasonExpirationUnknown
+		condition.Message = "Token expiration time is not known yet"
+	case !expiration.After(now):
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonTokenExpired
+		condition.Message = fmt.Sprintf("Token expired at %s", expiration.UTC().Format(time.RFC3339))
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonTokenValid
+		condition.Message = fmt.Sprintf("Token expires at %s", expiration.UTC().Format(time.RFC3339))
+	}
+
+	meta.SetStatusCondition(&token.Status.Conditions, condition)
+}
+
+// setValidCondition sets the Valid condition from the last validity check.
+func setValidCondition(token *tokenrenewerv1.Token, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&token.Status.Conditions, metav1.Condition{
+		Type:    tokenrenewerv1.ConditionValid,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// setSuspendedCondition sets the Suspended condition from spec.suspend.
+func setSuspendedCondition(token *tokenrenewerv1.Token) {
+	condition := metav1.Condition{
+		Type:    tokenrenewerv1.ConditionSuspended,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonNotSuspended,
+		Message: "Token is reconciled",
+	}
+	if token.Spec.Suspend {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reasonSuspended
+		condition.Message = "Validity checks and renewals are suspended"
+	}
+
+	meta.SetStatusCondition(&token.Status.Conditions, condition)
+}
+
 // SetupWithManager sets up the controller with the Manager using a custom rate limiter.
 func (r *TokenReconciler) SetupWithManager(mgr ctrl.Manager, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &tokenrenewerv1.Token{}, secretRefNameField, secretRefName); err != nil {
+		return fmt.Errorf("unable to index Tokens by secret name: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&tokenrenewerv1beta1.Token{}).
+		For(&tokenrenewerv1.Token{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.tokensForSecret)).
+		Watches(&tokenrenewerv1.TokenSecretGrant{}, handler.EnqueueRequestsFromMapFunc(r.tokensForGrant)).
 		WithOptions(controller.Options{
 			RateLimiter: rateLimiter,
 		}).
 		Named("token").
 		Complete(r)
 }
+
+// secretRefNameField indexes Tokens by the name of their Secret.
+const secretRefNameField = ".spec.secretRef.name"
+
+// secretRefName is the index function of secretRefNameField.
+func secretRefName(obj client.Object) []string {
+	token, ok := obj.(*tokenrenewerv1.Token)
+	if !ok {
+		return nil
+	}
+	return []string{token.Spec.SecretRef.Name}
+}
+
+// tokensForSecret maps a Secret to the Tokens writing it, so that a token
+// replaced out of band gets its expiration time read again.
+func (r *TokenReconciler) tokensForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	tokens := &tokenrenewerv1.TokenList{}
+	if err := r.List(ctx, tokens, client.MatchingFields{secretRefNameField: obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "unable to list Tokens for Secret", "secret", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, token := range tokens.Items {
+		if token.SecretNamespace() == obj.GetNamespace() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&token)})
+		}
+	}
+	return requests
+}
+
+// tokensForGrant maps a TokenSecretGrant to the Tokens referencing a Secret in
+// its namespace, so that creating or deleting a grant takes effect immediately.
+func (r *TokenReconciler) tokensForGrant(ctx context.Context, obj client.Object) []reconcile.Request {
+	tokens := &tokenrenewerv1.TokenList{}
+	if err := r.List(ctx, tokens); err != nil {
+		logf.FromContext(ctx).Error(err, "unable to list Tokens for TokenSecretGrant", "grant", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, token := range tokens.Items {
+		if token.Namespace != obj.GetNamespace() && token.SecretNamespace() == obj.GetNamespace() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&token)})
+		}
+	}
+	return requests
+}
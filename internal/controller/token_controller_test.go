@@ -22,33 +22,57 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	tokenrenewerv1beta1 "github.com/guilhem/token-renewer/api/v1beta1"
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
 	"github.com/guilhem/token-renewer/internal/providers"
+	"github.com/guilhem/token-renewer/shared"
 )
 
 // mockProvider implements the TokenProvider interface for testing
-type mockProvider struct{}
+type mockProvider struct {
+	revoked         []shared.Metadata
+	revokedPrevious []shared.Metadata
+	credentials     shared.Credentials
+	idempotencyKeys []string
+	validityErr     error
+}
 
-func (m *mockProvider) RenewToken(ctx context.Context, metadata, token string) (newToken string, newMetadata string, expiration *time.Time, err error) {
+func (m *mockProvider) RenewToken(ctx context.Context, req shared.RenewRequest) (shared.RenewResult, error) {
+	m.idempotencyKeys = append(m.idempotencyKeys, req.IdempotencyKey)
 	// Return a new token with a far future expiration
 	exp := time.Now().Add(24 * time.Hour)
-	return "new-test-token", metadata, &exp, nil
+	return shared.RenewResult{Token: "new-test-token", Metadata: req.Metadata, Expiration: &exp, PreviousRetained: req.RetainPrevious}, nil
 }
 
-func (m *mockProvider) GetTokenValidity(ctx context.Context, metadata, token string) (expiration *time.Time, err error) {
+func (m *mockProvider) GetTokenValidity(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) (expiration *time.Time, err error) {
+	m.credentials = credentials
+	if m.validityErr != nil {
+		return nil, m.validityErr
+	}
 	// Return a far future expiration time
 	exp := time.Now().Add(24 * time.Hour)
 	return &exp, nil
 }
 
+func (m *mockProvider) RevokeToken(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) error {
+	m.revoked = append(m.revoked, metadata)
+	return nil
+}
+
+func (m *mockProvider) RevokePreviousToken(ctx context.Context, previous shared.Metadata, token string, credentials shared.Credentials) error {
+	m.revokedPrevious = append(m.revokedPrevious, previous)
+	return nil
+}
+
 var _ = Describe("Token Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
@@ -59,7 +83,7 @@ var _ = Describe("Token Controller", func() {
 			Name:      resourceName,
 			Namespace: "default", // TODO(user):Modify as needed
 		}
-		token := &tokenrenewerv1beta1.Token{}
+		token := &tokenrenewerv1.Token{}
 
 		BeforeEach(func() {
 			By("creating the custom resource for the Kind Token")
@@ -77,18 +101,18 @@ var _ = Describe("Token Controller", func() {
 				}
 				Expect(k8sClient.Create(ctx, secret)).To(Succeed())
 
-				resource := &tokenrenewerv1beta1.Token{
+				resource := &tokenrenewerv1.Token{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					Spec: tokenrenewerv1beta1.TokenSpec{
-						Provider: tokenrenewerv1beta1.ProviderSpec{
+					Spec: tokenrenewerv1.TokenSpec{
+						Provider: tokenrenewerv1.ProviderSpec{
 							Name: "test-provider",
 						},
 						Metadata: "test-metadata",
-						Renewval: tokenrenewerv1beta1.RenewvalSpec{},
-						SecretRef: corev1.LocalObjectReference{
+						Renewal:  tokenrenewerv1.RenewalSpec{},
+						SecretRef: tokenrenewerv1.SecretReference{
 							Name: "test-secret",
 						},
 					},
@@ -99,12 +123,16 @@ var _ = Describe("Token Controller", func() {
 
 		AfterEach(func() {
 			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &tokenrenewerv1beta1.Token{}
+			resource := &tokenrenewerv1.Token{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance Token")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
@@ -130,5 +158,550 @@ var _ = Describe("Token Controller", func() {
 			})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("should report Ready and not Expired conditions after a successful reconcile", func() {
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, tokenrenewerv1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, tokenrenewerv1.ConditionDegraded)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, tokenrenewerv1.ConditionExpired)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, tokenrenewerv1.ConditionRenewing)).To(BeTrue())
+		})
+
+		It("should report the failure reason in the conditions when the provider is missing", func() {
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providers.NewProvidersManager(),
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			ready := meta.FindStatusCondition(resource.Status.Conditions, tokenrenewerv1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal(reasonProviderNotFound))
+			Expect(ready.Message).To(Equal(err.Error()))
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, tokenrenewerv1.ConditionDegraded)).To(BeTrue())
+		})
+
+		It("should read the configured key and copy the token to additional keys", func() {
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			secret.Data = map[string][]byte{"api-key": []byte("test-token-value")}
+			Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.SecretRef.Key = "api-key"
+			resource.Spec.SecretRef.AdditionalKeys = []string{"password", "LINODE_TOKEN"}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("api-key", []byte("test-token-value")))
+			Expect(secret.Data).To(HaveKeyWithValue("password", []byte("test-token-value")))
+			Expect(secret.Data).To(HaveKeyWithValue("LINODE_TOKEN", []byte("test-token-value")))
+		})
+
+		It("should renew once when the renew-requested-at annotation is set", func() {
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Annotations = map[string]string{
+				tokenrenewerv1.AnnotationRenewRequestedAt: "2025-01-01T00:00:00Z",
+			}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.LastHandledRenewRequest).To(Equal("2025-01-01T00:00:00Z"))
+			Expect(resource.Status.LastRenewalTime).NotTo(BeNil())
+			Expect(resource.Status.History).To(HaveLen(1))
+			Expect(resource.Status.History[0].Outcome).To(Equal(tokenrenewerv1.RenewalSucceeded))
+			Expect(resource.Status.History[0].Fingerprint).To(Equal(fingerprint("new-test-token")))
+			ready := meta.FindStatusCondition(resource.Status.Conditions, tokenrenewerv1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Reason).To(Equal(reasonTokenRenewed))
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("token", []byte("new-test-token")))
+
+			By("reconciling again with the same annotation value")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			ready = meta.FindStatusCondition(resource.Status.Conditions, tokenrenewerv1.ConditionReady)
+			Expect(ready.Reason).To(Equal(reasonTokenValid))
+		})
+
+		It("should read the expiration time again when the token is replaced out of band", func() {
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.TokenFingerprint).To(Equal(fingerprint("test-token-value")))
+
+			By("replacing the token in the Secret")
+			// Creation timestamps have a one second resolution
+			time.Sleep(time.Second)
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			secret.Data["token"] = []byte("manual-token")
+			Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.TokenFingerprint).To(Equal(fingerprint("manual-token")))
+		})
+
+		It("should keep the previous token during the overlap and revoke it afterwards", func() {
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Annotations = map[string]string{
+				tokenrenewerv1.AnnotationRenewRequestedAt: "2025-01-01T00:00:00Z",
+			}
+			resource.Spec.Renewal.Overlap = metav1.Duration{Duration: time.Hour}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			provider := &mockProvider{}
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", provider)
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.PendingRevocations).To(HaveLen(1))
+			Expect(resource.Status.PendingRevocations[0].Metadata.Metadata).To(Equal("test-metadata"))
+			Expect(resource.Status.PendingRevocations[0].Fingerprint).To(Equal(fingerprint("test-token-value")))
+			Expect(provider.revokedPrevious).To(BeEmpty())
+			Expect(provider.idempotencyKeys).To(ConsistOf(string(resource.UID) + "/1"))
+			Expect(resource.Status.RotationGeneration).To(Equal(int64(1)))
+			Expect(resource.Status.RenewalIdempotencyKey).To(BeEmpty())
+
+			By("reconciling once the overlap has ended")
+			resource.Status.PendingRevocations[0].RevokeAfter = metav1.NewTime(time.Now().Add(-time.Minute))
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.PendingRevocations).To(BeEmpty())
+			Expect(provider.revokedPrevious).To(ConsistOf(shared.Metadata{Value: "test-metadata"}))
+		})
+
+		It("should leave the Secret untouched and not requeue while suspended", func() {
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Suspend = true
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			// No provider is registered: a suspended Token must not need it
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providers.NewProvidersManager(),
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, tokenrenewerv1.ConditionSuspended)).To(BeTrue())
+			Expect(resource.Status.ExpirationTime.IsZero()).To(BeTrue())
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("token", []byte("test-token-value")))
+		})
+
+		It("should revoke the provider token through the finalizer on deletion", func() {
+			revokedName := types.NamespacedName{Name: "test-revoked", Namespace: "default"}
+			resource := &tokenrenewerv1.Token{
+				ObjectMeta: metav1.ObjectMeta{Name: revokedName.Name, Namespace: revokedName.Namespace},
+				Spec: tokenrenewerv1.TokenSpec{
+					Provider:       tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+					Metadata:       "test-metadata",
+					SecretRef:      tokenrenewerv1.SecretReference{Name: "test-secret"},
+					DeletionPolicy: tokenrenewerv1.DeletionPolicyRevoke,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			providersManager := providers.NewProvidersManager()
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			By("adding the finalizer")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: revokedName})
+			Expect(err).To(HaveOccurred()) // the provider is not connected yet
+			Expect(k8sClient.Get(ctx, revokedName, resource)).To(Succeed())
+			Expect(resource.Finalizers).To(ContainElement(tokenrenewerv1.FinalizerRevoke))
+
+			By("holding the deletion while the provider is not connected")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: revokedName})
+			Expect(err).To(HaveOccurred())
+			Expect(k8sClient.Get(ctx, revokedName, resource)).To(Succeed())
+			ready := meta.FindStatusCondition(resource.Status.Conditions, tokenrenewerv1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Reason).To(Equal(reasonRevokePending))
+
+			By("revoking once the provider is connected")
+			mockProv := &mockProvider{}
+			providersManager.RegisterPlugin("test-provider", mockProv)
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: revokedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockProv.revoked).To(HaveLen(1))
+			Expect(mockProv.revoked[0].Value).To(Equal("test-metadata"))
+
+			err = k8sClient.Get(ctx, revokedName, resource)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should create the missing Secret from the bootstrap Secret", func() {
+			bootstrappedName := types.NamespacedName{Name: "test-bootstrapped", Namespace: "default"}
+			resource := &tokenrenewerv1.Token{
+				ObjectMeta: metav1.ObjectMeta{Name: bootstrappedName.Name, Namespace: bootstrappedName.Namespace},
+				Spec: tokenrenewerv1.TokenSpec{
+					Provider:           tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+					Metadata:           "test-metadata",
+					SecretRef:          tokenrenewerv1.SecretReference{Name: "bootstrapped-secret"},
+					BootstrapSecretRef: &tokenrenewerv1.BootstrapSecretReference{Name: "test-secret"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				// envtest runs no garbage collector
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "bootstrapped-secret", Namespace: "default"},
+				})).To(Succeed())
+			})
+
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: bootstrappedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, bootstrappedName, resource)).To(Succeed())
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "bootstrapped-secret", Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("token", []byte("test-token-value")))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].UID).To(Equal(resource.UID))
+		})
+
+		It("should refuse a Secret of another namespace until a TokenSecretGrant allows it", func() {
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.SecretRef.Namespace = "kube-public"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			ready := meta.FindStatusCondition(resource.Status.Conditions, tokenrenewerv1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Reason).To(Equal(reasonSecretNotGranted))
+
+			By("granting the reference")
+			grant := &tokenrenewerv1.TokenSecretGrant{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-grant", Namespace: "kube-public"},
+				Spec: tokenrenewerv1.TokenSecretGrantSpec{
+					From: []tokenrenewerv1.TokenSecretGrantFrom{{Namespace: "default", Name: resourceName}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, grant)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, grant)).To(Succeed())
+			})
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			ready = meta.FindStatusCondition(resource.Status.Conditions, tokenrenewerv1.ConditionReady)
+			Expect(ready.Reason).To(Equal(reasonSecretNotFound))
+		})
+
+		It("should commit a renewal staged by an interrupted reconciliation", func() {
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("staging a renewed token that never reached the Secret")
+			data, err := encodeStagedRenewal(&stagedRenewal{
+				Token:               "staged-token",
+				Metadata:            tokenrenewerv1.ProviderMetadata{Metadata: "staged-metadata"},
+				PreviousMetadata:    tokenrenewerv1.ProviderMetadata{Metadata: "test-metadata"},
+				PreviousFingerprint: fingerprint("test-token-value"),
+				ExpirationTime:      metav1.NewTime(time.Now().Add(30 * 24 * time.Hour)),
+				IssueTime:           metav1.Now(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			staging := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: stagingSecretName(resource), Namespace: "default"},
+				Data:       data,
+			}
+			Expect(k8sClient.Create(ctx, staging)).To(Succeed())
+
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			Expect(string(secret.Data["token"])).To(Equal("staged-token"))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(providerMetadata(resource).Value).To(Equal("staged-metadata"))
+			Expect(resource.Status.TokenFingerprint).To(Equal(fingerprint("staged-token")))
+			Expect(resource.Status.History).NotTo(BeEmpty())
+			Expect(resource.Status.History[0].Outcome).To(Equal(tokenrenewerv1.RenewalSucceeded))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: staging.Name, Namespace: "default"}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should restart the rollout targets still running with a previous token", func() {
+			labels := map[string]string{"app": "rollout-test"}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "rollout-test", Namespace: "default"},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "app", Image: "busybox"}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, deployment)).To(Succeed())
+			})
+
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.RolloutTargets = []tokenrenewerv1.RolloutTarget{{
+				Kind: tokenrenewerv1.RolloutKindDeployment,
+				Name: "rollout-test",
+			}}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", &mockProvider{})
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("recording the Deployment with the adopted token without restarting it")
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "rollout-test", Namespace: "default"}, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).NotTo(HaveKey(tokenrenewerv1.AnnotationTokenFingerprint))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Rollouts).To(HaveLen(1))
+			Expect(resource.Status.Rollouts[0].Fingerprint).To(Equal(fingerprint("test-token-value")))
+			Expect(resource.Status.Rollouts[0].Time).To(BeNil())
+
+			By("replacing the token in the Secret")
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-secret", Namespace: "default"}, secret)).To(Succeed())
+			secret.Data["token"] = []byte("manual-token")
+			Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "rollout-test", Namespace: "default"}, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue(tokenrenewerv1.AnnotationTokenFingerprint, fingerprint("manual-token")))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Rollouts).To(HaveLen(1))
+			Expect(resource.Status.Rollouts[0].Fingerprint).To(Equal(fingerprint("manual-token")))
+			Expect(resource.Status.Rollouts[0].Time).NotTo(BeNil())
+		})
+
+		It("should pass the credentials secret entries to the provider", func() {
+			resource := &tokenrenewerv1.Token{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.CredentialsSecretRef = &tokenrenewerv1.CredentialsSecretReference{Name: "test-credentials"}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			provider := &mockProvider{}
+			providersManager := providers.NewProvidersManager()
+			providersManager.RegisterPlugin("test-provider", provider)
+
+			controllerReconciler := &TokenReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvidersManager: providersManager,
+				Recorder:         record.NewFakeRecorder(10),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			ready := meta.FindStatusCondition(resource.Status.Conditions, tokenrenewerv1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Reason).To(Equal(reasonCredentialsNotFound))
+
+			By("creating the credentials Secret")
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("admin-token")},
+			}
+			Expect(k8sClient.Create(ctx, credentials)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, credentials)).To(Succeed())
+			})
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider.credentials).To(HaveKeyWithValue("token", "admin-token"))
+		})
 	})
 })
@@ -1,12 +1,32 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+	"github.com/guilhem/token-renewer/internal/providers"
+	webhooktokenrenewerv1 "github.com/guilhem/token-renewer/internal/webhook/v1"
+	"github.com/guilhem/token-renewer/shared"
 )
 
 // ============================================================================
@@ -410,3 +430,766 @@ func TestSecretDeletionHandling(t *testing.T) {
 		}
 	})
 }
+
+// ============================================================================
+// Status Condition Tests
+// ============================================================================
+
+// TestSetExpiredCondition tests the Expired condition derived from the expiration time
+func TestSetExpiredCondition(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		expiration metav1.Time
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "unknown_expiration",
+			expiration: metav1.Time{},
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: reasonExpirationUnknown,
+		},
+		{
+			name:       "expired_token",
+			expiration: metav1.NewTime(now.Add(-time.Minute)),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: reasonTokenExpired,
+		},
+		{
+			name:       "expires_now",
+			expiration: metav1.NewTime(now),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: reasonTokenExpired,
+		},
+		{
+			name:       "valid_token",
+			expiration: metav1.NewTime(now.Add(time.Hour)),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonTokenValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &tokenrenewerv1.Token{}
+			token.Status.ExpirationTime = tt.expiration
+
+			setExpiredCondition(token, now)
+
+			condition := meta.FindStatusCondition(token.Status.Conditions, tokenrenewerv1.ConditionExpired)
+			if condition == nil {
+				t.Fatal("Expired condition not set")
+			}
+			if condition.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", condition.Status, tt.wantStatus)
+			}
+			if condition.Reason != tt.wantReason {
+				t.Errorf("reason = %v, want %v", condition.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+// TestSetSuspendedCondition tests that the Suspended condition follows spec.suspend
+func TestSetSuspendedCondition(t *testing.T) {
+	token := &tokenrenewerv1.Token{}
+
+	token.Spec.Suspend = true
+	setSuspendedCondition(token)
+	if !meta.IsStatusConditionTrue(token.Status.Conditions, tokenrenewerv1.ConditionSuspended) {
+		t.Errorf("Suspended condition not True: %+v", token.Status.Conditions)
+	}
+
+	token.Spec.Suspend = false
+	setSuspendedCondition(token)
+	if !meta.IsStatusConditionFalse(token.Status.Conditions, tokenrenewerv1.ConditionSuspended) {
+		t.Errorf("Suspended condition not False after resuming: %+v", token.Status.Conditions)
+	}
+}
+
+// TestProviderMetadataPrecedence tests that the status metadata wins over the spec seed
+func TestProviderMetadataPrecedence(t *testing.T) {
+	token := &tokenrenewerv1.Token{}
+	token.Spec.Metadata = "100"
+
+	if got := providerMetadata(token); got.Value != "100" {
+		t.Fatalf("unseeded token: got %q, want spec value", got.Value)
+	}
+
+	seedProviderMetadata(token)
+	if token.Status.CurrentMetadata == nil || token.Status.CurrentMetadata.Metadata != "100" {
+		t.Fatalf("status not seeded from spec: %+v", token.Status.CurrentMetadata)
+	}
+
+	setProviderMetadata(token, shared.Metadata{Value: "200"})
+	if token.Spec.Metadata != "100" {
+		t.Errorf("spec metadata modified: %q", token.Spec.Metadata)
+	}
+	if got := providerMetadata(token); got.Value != "200" {
+		t.Errorf("after renewal: got %q, want status value", got.Value)
+	}
+
+	// Seeding again with an unchanged spec keeps the renewed metadata
+	seedProviderMetadata(token)
+	if got := providerMetadata(token); got.Value != "200" {
+		t.Errorf("re-seed with unchanged spec: got %q, want 200", got.Value)
+	}
+
+	// A changed spec re-seeds the status
+	token.Spec.Metadata = "300"
+	if got := providerMetadata(token); got.Value != "300" {
+		t.Errorf("changed spec: got %q, want 300", got.Value)
+	}
+	seedProviderMetadata(token)
+	if token.Status.CurrentMetadata.Metadata != "300" {
+		t.Errorf("status not re-seeded: %q", token.Status.CurrentMetadata.Metadata)
+	}
+}
+
+// TestMetadataSeedHash tests that the seed hash ignores map ordering
+func TestMetadataSeedHash(t *testing.T) {
+	a := &tokenrenewerv1.Token{}
+	a.Spec.MetadataFields = map[string]string{"id": "1", "region": "eu"}
+	b := &tokenrenewerv1.Token{}
+	b.Spec.MetadataFields = map[string]string{"region": "eu", "id": "1"}
+	c := &tokenrenewerv1.Token{}
+	c.Spec.MetadataFields = map[string]string{"id": "2", "region": "eu"}
+
+	if metadataSeedHash(a) != metadataSeedHash(b) {
+		t.Error("hash depends on map ordering")
+	}
+	if metadataSeedHash(a) == metadataSeedHash(c) {
+		t.Error("hash does not change with the metadata")
+	}
+}
+
+// TestRenewalTime tests the renewal time computed for each renewal policy
+func TestRenewalTime(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiration := issued.Add(100 * time.Hour)
+
+	tests := []struct {
+		name    string
+		renewal tokenrenewerv1.RenewalSpec
+		issued  time.Time
+		want    time.Time
+	}{
+		{
+			name:    "before_duration",
+			renewal: tokenrenewerv1.RenewalSpec{BeforeDuration: metav1.Duration{Duration: 24 * time.Hour}},
+			issued:  issued,
+			want:    expiration.Add(-24 * time.Hour),
+		},
+		{
+			name:    "fraction",
+			renewal: tokenrenewerv1.RenewalSpec{RenewAtFraction: "0.66"},
+			issued:  issued,
+			want:    issued.Add(66 * time.Hour),
+		},
+		{
+			name: "fraction_with_floor",
+			renewal: tokenrenewerv1.RenewalSpec{
+				RenewAtFraction: "0.9",
+				MinRemaining:    metav1.Duration{Duration: 20 * time.Hour},
+			},
+			issued: issued,
+			want:   expiration.Add(-20 * time.Hour),
+		},
+		{
+			name: "fraction_floor_not_reached",
+			renewal: tokenrenewerv1.RenewalSpec{
+				RenewAtFraction: "0.5",
+				MinRemaining:    metav1.Duration{Duration: 20 * time.Hour},
+			},
+			issued: issued,
+			want:   issued.Add(50 * time.Hour),
+		},
+		{
+			name: "fraction_unknown_issue_time",
+			renewal: tokenrenewerv1.RenewalSpec{
+				RenewAtFraction: "0.5",
+				MinRemaining:    metav1.Duration{Duration: 10 * time.Hour},
+			},
+			want: expiration.Add(-10 * time.Hour),
+		},
+		{
+			name:    "before_duration_longer_than_lifetime",
+			renewal: tokenrenewerv1.RenewalSpec{BeforeDuration: metav1.Duration{Duration: 100 * time.Hour}},
+			issued:  issued,
+			want:    issued.Add(50 * time.Hour),
+		},
+		{
+			name: "floor_longer_than_lifetime",
+			renewal: tokenrenewerv1.RenewalSpec{
+				RenewAtFraction: "0.5",
+				MinRemaining:    metav1.Duration{Duration: 200 * time.Hour},
+			},
+			issued: issued,
+			want:   issued.Add(50 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &tokenrenewerv1.Token{}
+			token.Spec.Renewal = tt.renewal
+			token.Status.IssueTime = metav1.NewTime(tt.issued)
+			token.Status.ExpirationTime = metav1.NewTime(expiration)
+
+			got, err := renewalTime(token)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("renewalTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAppendHistory tests the bounded renewal history
+func TestAppendHistory(t *testing.T) {
+	record := func(outcome tokenrenewerv1.RenewalOutcome, message string, minute int) tokenrenewerv1.RenewalRecord {
+		return tokenrenewerv1.RenewalRecord{
+			Time:    metav1.NewTime(time.Date(2025, 1, 1, 0, minute, 0, 0, time.UTC)),
+			Outcome: outcome,
+			Message: message,
+		}
+	}
+
+	t.Run("newest_first_and_bounded", func(t *testing.T) {
+		limit := int32(2)
+		token := &tokenrenewerv1.Token{Spec: tokenrenewerv1.TokenSpec{HistoryLimit: &limit}}
+		for minute := range 3 {
+			appendHistory(token, record(tokenrenewerv1.RenewalSucceeded, "", minute))
+		}
+		if len(token.Status.History) != 2 {
+			t.Fatalf("history length = %d, want 2", len(token.Status.History))
+		}
+		if token.Status.History[0].Time.Minute() != 2 || token.Status.History[1].Time.Minute() != 1 {
+			t.Errorf("unexpected order: %+v", token.Status.History)
+		}
+	})
+
+	t.Run("default_limit", func(t *testing.T) {
+		token := &tokenrenewerv1.Token{}
+		for minute := range 20 {
+			appendHistory(token, record(tokenrenewerv1.RenewalSucceeded, "", minute))
+		}
+		if len(token.Status.History) != tokenrenewerv1.DefaultHistoryLimit {
+			t.Errorf("history length = %d, want %d", len(token.Status.History), tokenrenewerv1.DefaultHistoryLimit)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		limit := int32(0)
+		token := &tokenrenewerv1.Token{Spec: tokenrenewerv1.TokenSpec{HistoryLimit: &limit}}
+		appendHistory(token, record(tokenrenewerv1.RenewalSucceeded, "", 0))
+		if token.Status.History != nil {
+			t.Errorf("history = %+v, want nil", token.Status.History)
+		}
+	})
+
+	t.Run("repeated_failures_collapse", func(t *testing.T) {
+		token := &tokenrenewerv1.Token{}
+		appendHistory(token, record(tokenrenewerv1.RenewalSucceeded, "", 0))
+		appendHistory(token, record(tokenrenewerv1.RenewalFailed, "provider unavailable", 1))
+		appendHistory(token, record(tokenrenewerv1.RenewalFailed, "provider unavailable", 2))
+		appendHistory(token, record(tokenrenewerv1.RenewalFailed, "token not found", 3))
+
+		if len(token.Status.History) != 3 {
+			t.Fatalf("history length = %d, want 3: %+v", len(token.Status.History), token.Status.History)
+		}
+		if got := token.Status.History[1]; got.Message != "provider unavailable" || got.Time.Minute() != 2 {
+			t.Errorf("repeated failure not collapsed: %+v", got)
+		}
+	})
+}
+
+// TestFingerprint tests that the fingerprint is stable and does not contain the token
+func TestFingerprint(t *testing.T) {
+	got := fingerprint("secret-token-value")
+	if got != fingerprint("secret-token-value") {
+		t.Error("fingerprint is not stable")
+	}
+	if got == fingerprint("other-token-value") {
+		t.Error("different tokens share a fingerprint")
+	}
+	if len(got) != len("sha256:")+16 {
+		t.Errorf("fingerprint = %q, want a sha256: prefix and 16 hex characters", got)
+	}
+}
+
+// TestSecretRefName tests the field index of Tokens by secret name
+func TestSecretRefName(t *testing.T) {
+	token := &tokenrenewerv1.Token{
+		Spec: tokenrenewerv1.TokenSpec{
+			SecretRef: tokenrenewerv1.SecretReference{Name: "app-secret", Namespace: "apps"},
+		},
+	}
+	if got := secretRefName(token); len(got) != 1 || got[0] != "app-secret" {
+		t.Errorf("secretRefName() = %v, want [app-secret]", got)
+	}
+	if got := secretRefName(&corev1.Secret{}); got != nil {
+		t.Errorf("secretRefName() = %v for a non-Token object, want nil", got)
+	}
+}
+
+// TestValidityCheckDue tests when the validity of a token is checked again
+func TestValidityCheckDue(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	recent := metav1.NewTime(now.Add(-30 * time.Minute))
+	old := metav1.NewTime(now.Add(-2 * time.Hour))
+
+	tests := []struct {
+		name       string
+		expiration metav1.Time
+		lastCheck  *metav1.Time
+		interval   time.Duration
+		want       bool
+	}{
+		{"disabled", metav1.NewTime(now.Add(time.Hour)), &old, 0, false},
+		{"unknown_expiration", metav1.Time{}, nil, time.Hour, false},
+		{"never_checked", metav1.NewTime(now.Add(time.Hour)), nil, time.Hour, true},
+		{"checked_recently", metav1.NewTime(now.Add(time.Hour)), &recent, time.Hour, false},
+		{"interval_elapsed", metav1.NewTime(now.Add(time.Hour)), &old, time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &tokenrenewerv1.Token{
+				Status: tokenrenewerv1.TokenStatus{
+					ExpirationTime:        tt.expiration,
+					LastValidityCheckTime: tt.lastCheck,
+				},
+			}
+			if got := validityCheckDue(token, tt.interval, now); got != tt.want {
+				t.Errorf("validityCheckDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ============================================================================
+// Reconciler Tests
+// ============================================================================
+
+// newFakeClient returns a fake client holding objs with the scheme of the
+// controllers. The requests go through funcs where they are set.
+func newFakeClient(t *testing.T, funcs interceptor.Funcs, objs ...client.Object) (client.WithWatch, *runtime.Scheme) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := tokenrenewerv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&tokenrenewerv1.Token{}, &tokenrenewerv1.ClusterToken{}).
+		WithInterceptorFuncs(funcs).
+		Build()
+	return c, scheme
+}
+
+// newFakeTokenReconciler returns a TokenReconciler on a fake client holding
+// objs, with no provider connected.
+func newFakeTokenReconciler(t *testing.T, funcs interceptor.Funcs, objs ...client.Object) (*TokenReconciler, *record.FakeRecorder) {
+	t.Helper()
+
+	c, scheme := newFakeClient(t, funcs, objs...)
+	recorder := record.NewFakeRecorder(10)
+	return &TokenReconciler{
+		Client:           c,
+		Scheme:           scheme,
+		Recorder:         recorder,
+		ProvidersManager: providers.NewProvidersManager(),
+	}, recorder
+}
+
+// TestFinalizeWithoutSecrets tests that the finalizer of a Token whose Secrets
+// are already gone, as when its namespace is deleted, is removed through the
+// validating webhook
+func TestFinalizeWithoutSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	now := metav1.Now()
+	token := &tokenrenewerv1.Token{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-token",
+			Namespace:         "default",
+			Finalizers:        []string{tokenrenewerv1.FinalizerRevoke},
+			DeletionTimestamp: &now,
+		},
+		Spec: tokenrenewerv1.TokenSpec{
+			Provider:             tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+			Metadata:             "12345",
+			SecretRef:            tokenrenewerv1.SecretReference{Name: "deleted-secret"},
+			CredentialsSecretRef: &tokenrenewerv1.CredentialsSecretReference{Name: "deleted-credentials"},
+			DeletionPolicy:       tokenrenewerv1.DeletionPolicyRevoke,
+		},
+	}
+
+	// The validating webhook admits every Token patch sent by the controller
+	validator := &webhooktokenrenewerv1.TokenCustomValidator{ProvidersManager: providers.NewProvidersManager()}
+	r, _ := newFakeTokenReconciler(t, interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if newToken, ok := obj.(*tokenrenewerv1.Token); ok {
+				oldToken := &tokenrenewerv1.Token{}
+				if err := c.Get(ctx, client.ObjectKeyFromObject(obj), oldToken); err != nil {
+					return err
+				}
+				if _, err := validator.ValidateUpdate(ctx, oldToken, newToken); err != nil {
+					return err
+				}
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	}, token)
+	validator.Client = r.Client
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(token)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(token), &tokenrenewerv1.Token{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Token was not finalized: %v", err)
+	}
+}
+
+// TestResumeRenewalSecretReplaced tests that a staged renewal does not overwrite
+// a Secret replaced out of band after the renewal was staged
+func TestResumeRenewalSecretReplaced(t *testing.T) {
+	ctx := context.Background()
+
+	token := &tokenrenewerv1.Token{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-token", Namespace: "default", UID: "token-uid"},
+		Spec: tokenrenewerv1.TokenSpec{
+			SecretRef: tokenrenewerv1.SecretReference{Name: "test-secret"},
+		},
+		Status: tokenrenewerv1.TokenStatus{TokenFingerprint: fingerprint("old-token")},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "default"},
+		Data:       map[string][]byte{tokenrenewerv1.DefaultSecretKey: []byte("replaced-token")},
+	}
+	staged := &stagedRenewal{
+		Token:               "staged-token",
+		PreviousFingerprint: fingerprint("old-token"),
+		ExpirationTime:      metav1.NewTime(time.Now().Add(24 * time.Hour)),
+		IssueTime:           metav1.Now(),
+	}
+
+	r, recorder := newFakeTokenReconciler(t, interceptor.Funcs{}, token, secret)
+	if err := r.stageRenewal(ctx, token, staged); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := r.resumeRenewal(ctx, token, secret, staged); err != nil {
+		t.Fatalf("resumeRenewal() error = %v", err)
+	}
+
+	got := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(secret), got); err != nil {
+		t.Fatal(err)
+	}
+	if value := string(got.Data[tokenrenewerv1.DefaultSecretKey]); value != "replaced-token" {
+		t.Errorf("secret token = %q, want the value replaced out of band", value)
+	}
+	err := r.Get(ctx, client.ObjectKey{Namespace: token.Namespace, Name: stagingSecretName(token)}, &corev1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("staging secret not deleted: %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("recorded %d events, want a %s event", len(recorder.Events), reasonRenewalDiscarded)
+	}
+}
+
+// TestRolloutAcrossNamespaces tests that a Token writing the Secret of another
+// namespace does not restart the workloads of that namespace
+func TestRolloutAcrossNamespaces(t *testing.T) {
+	ctx := context.Background()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "web",
+			Namespace:         "apps",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+	}
+	token := &tokenrenewerv1.Token{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-token", Namespace: "default"},
+		Spec: tokenrenewerv1.TokenSpec{
+			SecretRef: tokenrenewerv1.SecretReference{Name: "app-secret", Namespace: "apps"},
+			RolloutTargets: []tokenrenewerv1.RolloutTarget{
+				{Kind: tokenrenewerv1.RolloutKindDeployment, Name: "web"},
+			},
+		},
+		Status: tokenrenewerv1.TokenStatus{
+			TokenFingerprint: "0123456789abcdef",
+			IssueTime:        metav1.Now(),
+		},
+	}
+
+	r, recorder := newFakeTokenReconciler(t, interceptor.Funcs{}, deployment)
+
+	rollouts, err := r.rolloutWorkloads(ctx, token)
+	if err != nil {
+		t.Fatalf("rolloutWorkloads() error = %v", err)
+	}
+	if len(rollouts) != 0 {
+		t.Errorf("rollouts = %v, want none", rollouts)
+	}
+
+	got := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(deployment), got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Spec.Template.Annotations[tokenrenewerv1.AnnotationTokenFingerprint]; ok {
+		t.Error("the Deployment of the Secret namespace was restarted")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("recorded %d events, want a RolloutError event", len(recorder.Events))
+	}
+}
+
+// TestClusterTokenSyncToken tests that the Token defaulted by the webhook is not
+// updated again while the template of the ClusterToken is unchanged
+func TestClusterTokenSyncToken(t *testing.T) {
+	ctx := context.Background()
+
+	clusterToken := &tokenrenewerv1.ClusterToken{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-token", UID: "cluster-token-uid"},
+		Spec: tokenrenewerv1.ClusterTokenSpec{
+			Namespace: "default",
+			Token: tokenrenewerv1.TokenSpec{
+				Provider:  tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+				Metadata:  "12345",
+				SecretRef: tokenrenewerv1.SecretReference{Name: "test-secret"},
+			},
+		},
+	}
+
+	// The defaulting webhook defaults every Token written by the controller
+	defaulter := &webhooktokenrenewerv1.TokenCustomDefaulter{}
+	c, scheme := newFakeClient(t, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if token, ok := obj.(*tokenrenewerv1.Token); ok {
+				if err := defaulter.Default(ctx, token); err != nil {
+					return err
+				}
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if token, ok := obj.(*tokenrenewerv1.Token); ok {
+				if err := defaulter.Default(ctx, token); err != nil {
+					return err
+				}
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	})
+	r := &ClusterTokenReconciler{Client: c, Scheme: scheme}
+
+	newToken := func() *tokenrenewerv1.Token {
+		return &tokenrenewerv1.Token{ObjectMeta: metav1.ObjectMeta{Name: clusterToken.Name, Namespace: clusterToken.Spec.Namespace}}
+	}
+
+	if op, err := r.syncToken(ctx, clusterToken, newToken()); err != nil || op != controllerutil.OperationResultCreated {
+		t.Fatalf("first syncToken() = %v, %v, want %v", op, err, controllerutil.OperationResultCreated)
+	}
+	if op, err := r.syncToken(ctx, clusterToken, newToken()); err != nil || op != controllerutil.OperationResultNone {
+		t.Errorf("second syncToken() = %v, %v, want %v", op, err, controllerutil.OperationResultNone)
+	}
+}
+
+// TestRenewalMarginLongerThanLifetime tests that a token renewed with a margin
+// longer than its lifetime is not renewed again by the next reconciliation,
+// as triggered by the watch on the Secret the renewal wrote
+func TestRenewalMarginLongerThanLifetime(t *testing.T) {
+	ctx := context.Background()
+
+	now := metav1.Now()
+	token := &tokenrenewerv1.Token{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-token", Namespace: "default", UID: "token-uid"},
+		Spec: tokenrenewerv1.TokenSpec{
+			Provider:  tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+			Metadata:  "12345",
+			SecretRef: tokenrenewerv1.SecretReference{Name: "test-secret"},
+			Renewal:   tokenrenewerv1.RenewalSpec{BeforeDuration: metav1.Duration{Duration: 24 * time.Hour}},
+		},
+		Status: tokenrenewerv1.TokenStatus{
+			ExpirationTime:        metav1.NewTime(now.Add(time.Hour)),
+			IssueTime:             metav1.NewTime(now.Add(-23 * time.Hour)),
+			TokenFingerprint:      fingerprint("old-token"),
+			LastValidityCheckTime: &now,
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "default"},
+		Data:       map[string][]byte{tokenrenewerv1.DefaultSecretKey: []byte("old-token")},
+	}
+
+	// The mock provider renews tokens for 24 hours, as long as the margin
+	provider := &mockProvider{}
+	r, _ := newFakeTokenReconciler(t, interceptor.Funcs{}, token, secret)
+	r.ProvidersManager.RegisterPlugin("test-provider", provider)
+
+	for i := range 2 {
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(token)})
+		if err != nil {
+			t.Fatalf("Reconcile() #%d error = %v", i+1, err)
+		}
+		if result.RequeueAfter < 11*time.Hour {
+			t.Errorf("Reconcile() #%d RequeueAfter = %v, want half of the token lifetime", i+1, result.RequeueAfter)
+		}
+	}
+
+	if len(provider.idempotencyKeys) != 1 {
+		t.Errorf("token renewed %d times, want once", len(provider.idempotencyKeys))
+	}
+}
+
+// TestPeriodicValidityCheckError tests that a periodic validity check only
+// marks the token invalid when the provider reports it invalid
+func TestPeriodicValidityCheckError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "token_invalid",
+			err:        fmt.Errorf("%w: token not found", shared.ErrTokenInvalid),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: reasonTokenInvalid,
+		},
+		{
+			name:       "transport_error",
+			err:        errors.New("rpc error: code = Unavailable desc = connection refused"),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: reasonTokenValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			checked := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			token := &tokenrenewerv1.Token{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-token", Namespace: "default"},
+				Spec: tokenrenewerv1.TokenSpec{
+					Provider:  tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+					Metadata:  "12345",
+					SecretRef: tokenrenewerv1.SecretReference{Name: "test-secret"},
+					Renewal:   tokenrenewerv1.RenewalSpec{CheckInterval: &metav1.Duration{Duration: time.Hour}},
+				},
+				Status: tokenrenewerv1.TokenStatus{
+					ExpirationTime:        metav1.NewTime(time.Now().Add(24 * time.Hour)),
+					IssueTime:             checked,
+					TokenFingerprint:      fingerprint("token"),
+					LastValidityCheckTime: &checked,
+					Conditions: []metav1.Condition{{
+						Type:               tokenrenewerv1.ConditionValid,
+						Status:             metav1.ConditionTrue,
+						Reason:             reasonTokenValid,
+						LastTransitionTime: checked,
+					}},
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "default"},
+				Data:       map[string][]byte{tokenrenewerv1.DefaultSecretKey: []byte("token")},
+			}
+
+			r, _ := newFakeTokenReconciler(t, interceptor.Funcs{}, token, secret)
+			r.ProvidersManager.RegisterPlugin("test-provider", &mockProvider{validityErr: tt.err})
+
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(token)}); err == nil {
+				t.Fatal("Reconcile() error = nil, want the validity check error")
+			}
+
+			got := &tokenrenewerv1.Token{}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(token), got); err != nil {
+				t.Fatal(err)
+			}
+			valid := meta.FindStatusCondition(got.Status.Conditions, tokenrenewerv1.ConditionValid)
+			if valid == nil || valid.Status != tt.wantStatus || valid.Reason != tt.wantReason {
+				t.Errorf("Valid condition = %+v, want %s/%s", valid, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+// TestRolloutAdoption tests that the workloads matched for the first time are
+// recorded with the current token and only restarted once it changes
+func TestRolloutAdoption(t *testing.T) {
+	ctx := context.Background()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "web",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+	}
+	token := &tokenrenewerv1.Token{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-token", Namespace: "default"},
+		Spec: tokenrenewerv1.TokenSpec{
+			SecretRef: tokenrenewerv1.SecretReference{Name: "test-secret"},
+			RolloutTargets: []tokenrenewerv1.RolloutTarget{
+				{Kind: tokenrenewerv1.RolloutKindDeployment, Name: "web"},
+			},
+		},
+		Status: tokenrenewerv1.TokenStatus{
+			TokenFingerprint: fingerprint("adopted-token"),
+			IssueTime:        metav1.Now(),
+		},
+	}
+
+	r, _ := newFakeTokenReconciler(t, interceptor.Funcs{}, deployment)
+	restarted := func() string {
+		t.Helper()
+		got := &appsv1.Deployment{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(deployment), got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Spec.Template.Annotations[tokenrenewerv1.AnnotationTokenFingerprint]
+	}
+
+	rollouts, err := r.rolloutWorkloads(ctx, token)
+	if err != nil {
+		t.Fatalf("rolloutWorkloads() error = %v", err)
+	}
+	if len(rollouts) != 1 || rollouts[0].Fingerprint != fingerprint("adopted-token") || rollouts[0].Time != nil {
+		t.Errorf("rollouts = %+v, want the Deployment recorded with the adopted token", rollouts)
+	}
+	if value := restarted(); value != "" {
+		t.Errorf("Deployment restarted for the adopted token %s", value)
+	}
+
+	// The token is renewed after the Deployment was recorded
+	token.Status.Rollouts = rollouts
+	token.Status.TokenFingerprint = fingerprint("renewed-token")
+	token.Status.IssueTime = metav1.Now()
+
+	rollouts, err = r.rolloutWorkloads(ctx, token)
+	if err != nil {
+		t.Fatalf("rolloutWorkloads() error = %v", err)
+	}
+	if len(rollouts) != 1 || rollouts[0].Fingerprint != fingerprint("renewed-token") || rollouts[0].Time == nil {
+		t.Errorf("rollouts = %+v, want the Deployment restarted for the renewed token", rollouts)
+	}
+	if value := restarted(); value != fingerprint("renewed-token") {
+		t.Errorf("Deployment restarted for %q, want the renewed token", value)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics of the controller. They are
+// registered with the controller-runtime registry and served by its metrics
+// server.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "token_renewer"
+
+var (
+	// RenewalAttempts counts the renewals requested from the providers, by
+	// provider and reason the renewal was started for.
+	RenewalAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "renewal_attempts_total",
+		Help:      "Number of token renewals attempted, by provider and reason.",
+	}, []string{"provider", "reason"})
+
+	// RenewalSuccesses counts the renewals committed to the target Secret, by
+	// provider and reason the renewal was started for.
+	RenewalSuccesses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "renewal_successes_total",
+		Help:      "Number of token renewals committed, by provider and reason.",
+	}, []string{"provider", "reason"})
+
+	// RenewalFailures counts the failed renewals, by provider and failure reason.
+	RenewalFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "renewal_failures_total",
+		Help:      "Number of token renewals failed, by provider and failure reason.",
+	}, []string{"provider", "reason"})
+
+	// PluginsConnected is 1 for every plugin connected to the controller.
+	PluginsConnected = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "plugins_connected",
+		Help:      "Plugins connected to the controller, by name and version.",
+	}, []string{"plugin", "version"})
+
+	// PluginRPCDuration observes the latency of the RPCs sent to the plugins.
+	PluginRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "plugin_rpc_duration_seconds",
+		Help:      "Latency of the RPCs sent to the plugins, by plugin, method and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"plugin", "method", "result"})
+
+	// TokenExpiration reports the expiration of every Token.
+	TokenExpiration = newTokenExpiryCollector()
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		RenewalAttempts,
+		RenewalSuccesses,
+		RenewalFailures,
+		PluginsConnected,
+		PluginRPCDuration,
+		TokenExpiration,
+	)
+}
+
+// tokenExpiry is the expiration time of a Token and the provider managing it.
+type tokenExpiry struct {
+	provider   string
+	expiration time.Time
+}
+
+// TokenExpiryCollector exports the expiration timestamp of the Tokens and the
+// seconds left until then, computed when the metrics are scraped.
+type TokenExpiryCollector struct {
+	expirationDesc *prometheus.Desc
+	remainingDesc  *prometheus.Desc
+
+	mu     sync.Mutex
+	tokens map[types.NamespacedName]tokenExpiry
+	now    func() time.Time
+}
+
+func newTokenExpiryCollector() *TokenExpiryCollector {
+	labels := []string{"namespace", "name", "provider"}
+	return &TokenExpiryCollector{
+		expirationDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "token", "expiration_timestamp_seconds"),
+			"Expiration time of the token as a Unix timestamp.",
+			labels, nil),
+		remainingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "token", "seconds_to_expiry"),
+			"Seconds left until the token expires, negative once it has expired.",
+			labels, nil),
+		tokens: make(map[types.NamespacedName]tokenExpiry),
+		now:    time.Now,
+	}
+}
+
+// Set records the expiration time of a Token.
+func (c *TokenExpiryCollector) Set(token types.NamespacedName, provider string, expiration time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[token] = tokenExpiry{provider: provider, expiration: expiration}
+}
+
+// Delete forgets a deleted Token.
+func (c *TokenExpiryCollector) Delete(token types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tokens, token)
+}
+
+// Describe implements prometheus.Collector.
+func (c *TokenExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.expirationDesc
+	ch <- c.remainingDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *TokenExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for token, expiry := range c.tokens {
+		ch <- prometheus.MustNewConstMetric(c.expirationDesc, prometheus.GaugeValue,
+			float64(expiry.expiration.Unix()), token.Namespace, token.Name, expiry.provider)
+		ch <- prometheus.MustNewConstMetric(c.remainingDesc, prometheus.GaugeValue,
+			expiry.expiration.Sub(now).Seconds(), token.Namespace, token.Name, expiry.provider)
+	}
+}
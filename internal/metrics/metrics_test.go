@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestTokenExpiryCollector tests the expiration metrics computed at scrape time
+func TestTokenExpiryCollector(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newTokenExpiryCollector()
+	c.now = func() time.Time { return now }
+
+	key := types.NamespacedName{Namespace: "default", Name: "ci"}
+	c.Set(key, "linode", now.Add(time.Hour))
+	c.Set(types.NamespacedName{Namespace: "default", Name: "old"}, "linode", now.Add(-time.Minute))
+
+	want := `
+# HELP token_renewer_token_expiration_timestamp_seconds Expiration time of the token as a Unix timestamp.
+# TYPE token_renewer_token_expiration_timestamp_seconds gauge
+token_renewer_token_expiration_timestamp_seconds{name="ci",namespace="default",provider="linode"} 1.8934596e+09
+token_renewer_token_expiration_timestamp_seconds{name="old",namespace="default",provider="linode"} 1.89345594e+09
+# HELP token_renewer_token_seconds_to_expiry Seconds left until the token expires, negative once it has expired.
+# TYPE token_renewer_token_seconds_to_expiry gauge
+token_renewer_token_seconds_to_expiry{name="ci",namespace="default",provider="linode"} 3600
+token_renewer_token_seconds_to_expiry{name="old",namespace="default",provider="linode"} -60
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	c.Delete(key)
+	c.Delete(types.NamespacedName{Namespace: "default", Name: "old"})
+	if n := testutil.CollectAndCount(c); n != 0 {
+		t.Errorf("CollectAndCount() = %d after Delete, want 0", n)
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -31,8 +33,10 @@ import (
 
 	pluginframeworkv1 "github.com/guilhem/operator-plugin-framework/pluginframework/v1"
 	"github.com/guilhem/operator-plugin-framework/stream"
+	"github.com/guilhem/token-renewer/internal/metrics"
 	"github.com/guilhem/token-renewer/internal/providers"
 	shared "github.com/guilhem/token-renewer/shared"
+	"github.com/guilhem/token-renewer/shared/tracing"
 )
 
 // StreamServer runs inside the controller and only exposes the PluginStream RPC
@@ -115,13 +119,13 @@ type StreamHandler struct {
 	providersManager *providers.ProvidersManager
 
 	mu            sync.Mutex
-	activePlugins map[string]struct{}
+	activePlugins map[string]string
 }
 
 func NewStreamHandler(providersManager *providers.ProvidersManager) *StreamHandler {
 	return &StreamHandler{
 		providersManager: providersManager,
-		activePlugins:    make(map[string]struct{}),
+		activePlugins:    make(map[string]string),
 	}
 }
 
@@ -137,6 +141,18 @@ func (s *StreamHandler) GetTokenValidity(ctx context.Context, in *shared.GetToke
 	return nil, status.Errorf(codes.Unimplemented, "controller stream server only exposes PluginStream; plugins implement GetTokenValidity")
 }
 
+// RevokeToken revokes a token on the provider side.
+// This is implemented by plugins, not by the controller-side stream server.
+func (s *StreamHandler) RevokeToken(ctx context.Context, in *shared.RevokeTokenRequest) (*shared.RevokeTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "controller stream server only exposes PluginStream; plugins implement RevokeToken")
+}
+
+// RevokePreviousToken revokes the previous token kept by a two-phase rotation.
+// This is implemented by plugins, not by the controller-side stream server.
+func (s *StreamHandler) RevokePreviousToken(ctx context.Context, in *shared.RevokePreviousTokenRequest) (*shared.RevokePreviousTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "controller stream server only exposes PluginStream; plugins implement RevokePreviousToken")
+}
+
 // PluginStream handles a bidirectional stream with a plugin.
 // It uses the framework's StreamManager directly with the gRPC stream.
 func (s *StreamHandler) PluginStream(grpcStream grpc.BidiStreamingServer[pluginframeworkv1.PluginStreamMessage, pluginframeworkv1.PluginStreamMessage]) error {
@@ -149,8 +165,8 @@ func (s *StreamHandler) PluginStream(grpcStream grpc.BidiStreamingServer[pluginf
 		return err
 	}
 
-	pluginName := streamMgr.GetPluginName()
-	logger = logger.WithValues("plugin", pluginName, "version", streamMgr.GetPluginVersion())
+	pluginName, pluginVersion := streamMgr.GetPluginName(), streamMgr.GetPluginVersion()
+	logger = logger.WithValues("plugin", pluginName, "version", pluginVersion)
 	logger.Info("Plugin connected via stream")
 
 	// Create wrapper that implements TokenProvider using the stream manager
@@ -160,7 +176,7 @@ func (s *StreamHandler) PluginStream(grpcStream grpc.BidiStreamingServer[pluginf
 	}
 
 	// Register the plugin
-	s.registerPlugin(pluginName, wrapper)
+	s.registerPlugin(pluginName, pluginVersion, wrapper)
 	logger.Info("Plugin registered in provider manager")
 
 	// Keep the stream alive and listen for messages
@@ -174,18 +190,23 @@ func (s *StreamHandler) PluginStream(grpcStream grpc.BidiStreamingServer[pluginf
 	return streamMgr.ListenForMessages(ctx)
 }
 
-func (s *StreamHandler) registerPlugin(name string, provider shared.TokenProvider) {
+func (s *StreamHandler) registerPlugin(name, version string, provider shared.TokenProvider) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.activePlugins[name] = struct{}{}
+	if previous, ok := s.activePlugins[name]; ok {
+		metrics.PluginsConnected.DeleteLabelValues(name, previous)
+	}
+	s.activePlugins[name] = version
 	s.providersManager.RegisterPlugin(name, provider)
+	metrics.PluginsConnected.WithLabelValues(name, version).Set(1)
 }
 
 func (s *StreamHandler) unregisterPlugin(name string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	metrics.PluginsConnected.DeleteLabelValues(name, s.activePlugins[name])
 	delete(s.activePlugins, name)
 	s.providersManager.UnregisterPlugin(name)
 }
@@ -195,8 +216,9 @@ func (s *StreamHandler) DropAll() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for name := range s.activePlugins {
+	for name, version := range s.activePlugins {
 		s.providersManager.UnregisterPlugin(name)
+		metrics.PluginsConnected.DeleteLabelValues(name, version)
 		delete(s.activePlugins, name)
 	}
 }
@@ -224,31 +246,51 @@ type PluginClient struct {
 }
 
 // RenewToken renews a token via the plugin client.
-func (pc *PluginClient) RenewToken(ctx context.Context, metadata, token string) (string, string, *time.Time, error) {
-	resp, err := pc.client.RenewToken(ctx, &shared.RenewTokenRequest{
-		Metadata: metadata,
-		Token:    token,
-	})
+func (pc *PluginClient) RenewToken(ctx context.Context, req shared.RenewRequest) (shared.RenewResult, error) {
+	resp, err := pc.client.RenewToken(ctx, renewTokenRequest(req))
 	if err != nil {
-		return "", "", nil, err
+		return shared.RenewResult{}, err
 	}
-	expTime := resp.GetExpiration().AsTime()
-	return resp.GetToken(), resp.GetNewMetadata(), &expTime, nil
+	return renewResult(resp), nil
 }
 
 // GetTokenValidity returns the expiration time of a token via the plugin client.
-func (pc *PluginClient) GetTokenValidity(ctx context.Context, metadata, token string) (*time.Time, error) {
+func (pc *PluginClient) GetTokenValidity(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) (*time.Time, error) {
 	resp, err := pc.client.GetTokenValidity(ctx, &shared.GetTokenValidityRequest{
-		Metadata: metadata,
-		Token:    token,
+		Metadata:       metadata.Value,
+		MetadataFields: metadata.Fields,
+		Token:          token,
+		Credentials:    credentials,
 	})
 	if err != nil {
-		return nil, err
+		return nil, validityError(err)
 	}
 	expTime := resp.GetExpiration().AsTime()
 	return &expTime, nil
 }
 
+// RevokeToken revokes a token via the plugin client.
+func (pc *PluginClient) RevokeToken(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) error {
+	_, err := pc.client.RevokeToken(ctx, &shared.RevokeTokenRequest{
+		Metadata:       metadata.Value,
+		MetadataFields: metadata.Fields,
+		Token:          token,
+		Credentials:    credentials,
+	})
+	return err
+}
+
+// RevokePreviousToken revokes the previous token of a two-phase rotation via the plugin client.
+func (pc *PluginClient) RevokePreviousToken(ctx context.Context, previous shared.Metadata, token string, credentials shared.Credentials) error {
+	_, err := pc.client.RevokePreviousToken(ctx, &shared.RevokePreviousTokenRequest{
+		Metadata:       previous.Value,
+		MetadataFields: previous.Fields,
+		Token:          token,
+		Credentials:    credentials,
+	})
+	return err
+}
+
 var _ shared.TokenProvider = (*PluginClient)(nil)
 
 // StreamPluginClient implements shared.TokenProvider by using the framework's StreamManager.
@@ -259,37 +301,34 @@ type StreamPluginClient struct {
 }
 
 // RenewToken sends a RenewToken RPC call to the plugin via the stream manager.
-func (pc *StreamPluginClient) RenewToken(ctx context.Context, metadata, token string) (string, string, *time.Time, error) {
-	req := &shared.RenewTokenRequest{
-		Metadata: metadata,
-		Token:    token,
-	}
-
+func (pc *StreamPluginClient) RenewToken(ctx context.Context, req shared.RenewRequest) (shared.RenewResult, error) {
 	// Use stream manager to call RPC
-	respBytes, err := pc.streamMgr.CallRPC(ctx, "RenewToken", req)
+	respBytes, err := pc.callRPC(ctx, "RenewToken", renewTokenRequest(req))
 	if err != nil {
-		return "", "", nil, fmt.Errorf("RPC failed: %w", err)
+		return shared.RenewResult{}, fmt.Errorf("RPC failed: %w", err)
 	}
 
 	// Unmarshal response
 	resp := &shared.RenewTokenResponse{}
 	if err := proto.Unmarshal(respBytes, resp); err != nil {
-		return "", "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return shared.RenewResult{}, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	expTime := resp.GetExpiration().AsTime()
-	return resp.GetToken(), resp.GetNewMetadata(), &expTime, nil
+	return renewResult(resp), nil
 }
 
 // GetTokenValidity sends a GetTokenValidity RPC call to the plugin via the stream manager.
-func (pc *StreamPluginClient) GetTokenValidity(ctx context.Context, metadata, token string) (*time.Time, error) {
+func (pc *StreamPluginClient) GetTokenValidity(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) (*time.Time, error) {
 	req := &shared.GetTokenValidityRequest{
-		Metadata: metadata,
-		Token:    token,
+		Metadata:       metadata.Value,
+		MetadataFields: metadata.Fields,
+		Token:          token,
+		Credentials:    credentials,
 	}
 
-	// Use stream manager to call RPC
-	respBytes, err := pc.streamMgr.CallRPC(ctx, "GetTokenValidity", req)
+	// Use stream manager to call RPC. The stream does not carry the error
+	// codes of plugins, so its errors never report the token invalid
+	respBytes, err := pc.callRPC(ctx, "GetTokenValidity", req)
 	if err != nil {
 		return nil, fmt.Errorf("RPC failed: %w", err)
 	}
@@ -304,4 +343,106 @@ func (pc *StreamPluginClient) GetTokenValidity(ctx context.Context, metadata, to
 	return &expTime, nil
 }
 
+// RevokeToken sends a RevokeToken RPC call to the plugin via the stream manager.
+func (pc *StreamPluginClient) RevokeToken(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) error {
+	req := &shared.RevokeTokenRequest{
+		Metadata:       metadata.Value,
+		MetadataFields: metadata.Fields,
+		Token:          token,
+		Credentials:    credentials,
+	}
+
+	// Use stream manager to call RPC
+	if _, err := pc.callRPC(ctx, "RevokeToken", req); err != nil {
+		return fmt.Errorf("RPC failed: %w", err)
+	}
+
+	return nil
+}
+
+// RevokePreviousToken sends a RevokePreviousToken RPC call to the plugin via the stream manager.
+func (pc *StreamPluginClient) RevokePreviousToken(ctx context.Context, previous shared.Metadata, token string, credentials shared.Credentials) error {
+	req := &shared.RevokePreviousTokenRequest{
+		Metadata:       previous.Value,
+		MetadataFields: previous.Fields,
+		Token:          token,
+		Credentials:    credentials,
+	}
+
+	// Use stream manager to call RPC
+	if _, err := pc.callRPC(ctx, "RevokePreviousToken", req); err != nil {
+		return fmt.Errorf("RPC failed: %w", err)
+	}
+
+	return nil
+}
+
+// tracedRequest is a request carrying the trace context to the plugin.
+type tracedRequest interface {
+	proto.Message
+	SetTraceContext(traceContext map[string]string)
+}
+
+// callRPC sends an RPC call to the plugin via the stream manager, in a span
+// whose context is propagated to the plugin, and records its latency.
+func (pc *StreamPluginClient) callRPC(ctx context.Context, method string, req tracedRequest) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "CallRPC "+method, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", "barpilot.token_renewer.v1.TokenProviderService"),
+			attribute.String("rpc.method", method),
+			attribute.String("plugin", pc.pluginName),
+		))
+	req.SetTraceContext(shared.InjectTraceContext(ctx))
+
+	start := time.Now()
+	resp, err := pc.streamMgr.CallRPC(ctx, method, req)
+	tracing.End(span, err)
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metrics.PluginRPCDuration.WithLabelValues(pc.pluginName, method, result).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
 var _ shared.TokenProvider = (*StreamPluginClient)(nil)
+
+// renewTokenRequest returns the RenewToken request of a renewal.
+func renewTokenRequest(req shared.RenewRequest) *shared.RenewTokenRequest {
+	return &shared.RenewTokenRequest{
+		Metadata:       req.Metadata.Value,
+		MetadataFields: req.Metadata.Fields,
+		Token:          req.Token,
+		Credentials:    req.Credentials,
+		RetainPrevious: req.RetainPrevious,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+}
+
+// renewResult extracts the renewed token from a RenewToken response.
+func renewResult(resp *shared.RenewTokenResponse) shared.RenewResult {
+	expiration := resp.GetExpiration().AsTime()
+	return shared.RenewResult{
+		Token: resp.GetToken(),
+		Metadata: shared.Metadata{
+			Value:  resp.GetNewMetadata(),
+			Fields: resp.GetNewMetadataFields(),
+		},
+		Expiration:       &expiration,
+		PreviousRetained: resp.GetPreviousTokenRetained(),
+	}
+}
+
+// validityError wraps shared.ErrTokenInvalid in the GetTokenValidity errors
+// of plugins reporting the token revoked or unknown.
+func validityError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound, codes.Unauthenticated:
+		return fmt.Errorf("%w: %s", shared.ErrTokenInvalid, status.Convert(err).Message())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+	"github.com/guilhem/token-renewer/internal/providers"
+	"github.com/guilhem/token-renewer/shared"
+)
+
+// log is for logging in this package.
+var tokenlog = logf.Log.WithName("token-resource")
+
+// providerValidationTimeout bounds the provider call made during admission.
+const providerValidationTimeout = 5 * time.Second
+
+// SetupTokenWebhookWithManager registers the webhook for Token in the manager.
+func SetupTokenWebhookWithManager(mgr ctrl.Manager, providersManager *providers.ProvidersManager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&tokenrenewerv1.Token{}).
+		WithValidator(&TokenCustomValidator{
+			Client:           mgr.GetClient(),
+			ProvidersManager: providersManager,
+		}).
+		WithDefaulter(&TokenCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-token-renewer-barpilot-io-v1-token,mutating=true,failurePolicy=fail,sideEffects=None,groups=token-renewer.barpilot.io,resources=tokens,verbs=create;update,versions=v1,name=mtoken-v1.kb.io,admissionReviewVersions=v1
+
+// TokenCustomDefaulter sets default values on the Token when it is created or updated.
+type TokenCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &TokenCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Token.
+func (d *TokenCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	token, ok := obj.(*tokenrenewerv1.Token)
+	if !ok {
+		return fmt.Errorf("expected a Token object but got %T", obj)
+	}
+	tokenlog.Info("Defaulting for Token", "name", token.GetName())
+
+	token.Spec.SetDefaults()
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-token-renewer-barpilot-io-v1-token,mutating=false,failurePolicy=fail,sideEffects=None,groups=token-renewer.barpilot.io,resources=tokens,verbs=create;update,versions=v1,name=vtoken-v1.kb.io,admissionReviewVersions=v1
+
+// TokenCustomValidator validates the Token when it is created or updated. When
+// the provider is connected, it asks the provider to validate the metadata.
+type TokenCustomValidator struct {
+	Client           client.Reader
+	ProvidersManager *providers.ProvidersManager
+}
+
+var _ webhook.CustomValidator = &TokenCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Token.
+func (v *TokenCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	token, ok := obj.(*tokenrenewerv1.Token)
+	if !ok {
+		return nil, fmt.Errorf("expected a Token object but got %T", obj)
+	}
+	tokenlog.Info("Validation for Token upon creation", "name", token.GetName())
+
+	return v.validateToken(ctx, token, true, true)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Token.
+func (v *TokenCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	token, ok := newObj.(*tokenrenewerv1.Token)
+	if !ok {
+		return nil, fmt.Errorf("expected a Token object for the newObj but got %T", newObj)
+	}
+	oldToken, ok := oldObj.(*tokenrenewerv1.Token)
+	if !ok {
+		return nil, fmt.Errorf("expected a Token object for the oldObj but got %T", oldObj)
+	}
+	tokenlog.Info("Validation for Token upon update", "name", token.GetName())
+
+	// Metadata updates, such as the removal of the finalizer, must go through
+	// even when the Secrets are gone, for example while the namespace of the
+	// Token is deleted
+	if !token.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(token.Spec, oldToken.Spec) {
+		return nil, nil
+	}
+
+	// Only check the Secrets again when their references have changed
+	checkSecrets := token.Spec.SecretRef.Name != oldToken.Spec.SecretRef.Name ||
+		!reflect.DeepEqual(token.Spec.BootstrapSecretRef, oldToken.Spec.BootstrapSecretRef) ||
+		!reflect.DeepEqual(token.Spec.CredentialsSecretRef, oldToken.Spec.CredentialsSecretRef) ||
+		token.SecretNamespace() != oldToken.SecretNamespace()
+
+	// Only ask the provider again when what it validated has changed
+	checkProvider := checkSecrets ||
+		token.Spec.Provider != oldToken.Spec.Provider ||
+		token.Spec.Metadata != oldToken.Spec.Metadata ||
+		!reflect.DeepEqual(token.Spec.MetadataFields, oldToken.Spec.MetadataFields)
+
+	return v.validateToken(ctx, token, checkSecrets, checkProvider)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Token.
+func (v *TokenCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateToken validates the spec and, when checkSecrets is set, checks that
+// the Secrets exist. When checkProvider is set and the provider is connected,
+// it asks the provider to validate the metadata.
+func (v *TokenCustomValidator) validateToken(ctx context.Context, token *tokenrenewerv1.Token, checkSecrets, checkProvider bool) (admission.Warnings, error) {
+	specPath := field.NewPath("spec")
+	allErrs := validateSpec(&token.Spec, specPath)
+
+	secretRef := token.Spec.SecretRef
+	secretNamespace := token.SecretNamespace()
+	if secretRef.SetOwnerReference && secretNamespace != token.Namespace {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("secretRef", "setOwnerReference"), true,
+			"owner references cannot be set on a Secret of another namespace"))
+	}
+	if len(token.Spec.RolloutTargets) > 0 && secretNamespace != token.Namespace {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("rolloutTargets"),
+			"workloads are only restarted for a Secret in the namespace of the Token"))
+	}
+	if secretNamespace != token.Namespace {
+		// Without a grant, the Secret of another namespace must not be disclosed
+		grants := &tokenrenewerv1.TokenSecretGrantList{}
+		if err := v.Client.List(ctx, grants, client.InNamespace(secretNamespace)); err != nil {
+			return nil, apierrors.NewInternalError(fmt.Errorf("unable to list token secret grants: %w", err))
+		}
+		if !tokenrenewerv1.SecretReferenceGranted(token, grants.Items) {
+			if len(allErrs) > 0 {
+				return nil, apierrors.NewInvalid(schema.GroupKind{Group: tokenrenewerv1.GroupVersion.Group, Kind: "Token"}, token.Name, allErrs)
+			}
+			return admission.Warnings{fmt.Sprintf("no TokenSecretGrant in namespace %q allows this Token to reference secret %q", secretNamespace, secretRef.Name)}, nil
+		}
+	}
+
+	if !checkSecrets && !checkProvider {
+		if len(allErrs) > 0 {
+			return nil, apierrors.NewInvalid(schema.GroupKind{Group: tokenrenewerv1.GroupVersion.Group, Kind: "Token"}, token.Name, allErrs)
+		}
+		return nil, nil
+	}
+
+	secretPath := specPath.Child("secretRef", "name")
+	sourceName, sourceKey := secretRef.Name, secretRef.TokenKey()
+	secret := &corev1.Secret{}
+	err := v.Client.Get(ctx, client.ObjectKey{Namespace: secretNamespace, Name: secretRef.Name}, secret)
+	if bootstrapRef := token.Spec.BootstrapSecretRef; bootstrapRef != nil && apierrors.IsNotFound(err) {
+		// The controller creates the Secret from the bootstrap Secret
+		secretPath = specPath.Child("bootstrapSecretRef", "name")
+		sourceName, sourceKey = bootstrapRef.Name, bootstrapRef.TokenKey()
+		err = v.Client.Get(ctx, client.ObjectKey{Namespace: token.Namespace, Name: bootstrapRef.Name}, secret)
+	}
+	secretErr := err
+	if err != nil && checkSecrets {
+		if apierrors.IsNotFound(err) {
+			allErrs = append(allErrs, field.NotFound(secretPath, sourceName))
+		} else {
+			allErrs = append(allErrs, field.InternalError(secretPath, fmt.Errorf("unable to fetch secret: %w", err)))
+		}
+	}
+
+	var credentials shared.Credentials
+	if credentialsRef := token.Spec.CredentialsSecretRef; credentialsRef != nil {
+		credentialsPath := specPath.Child("credentialsSecretRef", "name")
+		credentialsSecret := &corev1.Secret{}
+		if err := v.Client.Get(ctx, client.ObjectKey{Namespace: token.Namespace, Name: credentialsRef.Name}, credentialsSecret); err != nil {
+			secretErr = err
+			switch {
+			case !checkSecrets:
+				// Left to the controller, which reports the missing Secret
+			case apierrors.IsNotFound(err):
+				allErrs = append(allErrs, field.NotFound(credentialsPath, credentialsRef.Name))
+			default:
+				allErrs = append(allErrs, field.InternalError(credentialsPath, fmt.Errorf("unable to fetch credentials secret: %w", err)))
+			}
+		}
+		credentials = make(shared.Credentials, len(credentialsSecret.Data))
+		for k, val := range credentialsSecret.Data {
+			credentials[k] = string(val)
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return nil, apierrors.NewInvalid(schema.GroupKind{Group: tokenrenewerv1.GroupVersion.Group, Kind: "Token"}, token.Name, allErrs)
+	}
+
+	if !checkProvider {
+		return nil, nil
+	}
+
+	providerName := token.Spec.Provider.Name
+	provider, err := v.ProvidersManager.GetProvider(providerName)
+	if err != nil {
+		return admission.Warnings{fmt.Sprintf("provider %q is not connected, metadata was not validated", providerName)}, nil
+	}
+
+	if secretErr != nil {
+		return admission.Warnings{fmt.Sprintf("unable to read the secrets of the token, metadata was not validated: %v", secretErr)}, nil
+	}
+
+	tokenValue := secret.Data[sourceKey]
+	if len(tokenValue) == 0 {
+		return admission.Warnings{fmt.Sprintf("secret %q has no token under key %q, metadata was not validated", sourceName, sourceKey)}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, providerValidationTimeout)
+	defer cancel()
+
+	metadata := shared.Metadata{Value: token.Spec.Metadata, Fields: token.Spec.MetadataFields}
+	if _, err := provider.GetTokenValidity(ctx, metadata, string(tokenValue), credentials); err != nil {
+		return nil, apierrors.NewInvalid(schema.GroupKind{Group: tokenrenewerv1.GroupVersion.Group, Kind: "Token"}, token.Name, field.ErrorList{
+			field.Invalid(specPath.Child("metadata"), token.Spec.Metadata, fmt.Sprintf("rejected by provider %q: %v", providerName, err)),
+		})
+	}
+
+	return nil, nil
+}
+
+// validateSpec validates the fields the CRD schema cannot fully check.
+func validateSpec(spec *tokenrenewerv1.TokenSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.Provider.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("provider", "name"), "provider name must not be empty"))
+	}
+
+	renewalPath := path.Child("renewal")
+	renewal := spec.Renewal
+	if renewal.BeforeDuration.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(renewalPath.Child("beforeDuration"), renewal.BeforeDuration.String(), "must not be negative"))
+	}
+	if renewal.MinRemaining.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(renewalPath.Child("minRemaining"), renewal.MinRemaining.String(), "must not be negative"))
+	}
+	if renewal.CheckInterval != nil && renewal.CheckInterval.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(renewalPath.Child("checkInterval"), renewal.CheckInterval.String(), "must not be negative"))
+	}
+	if renewal.Overlap.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(renewalPath.Child("overlap"), renewal.Overlap.String(), "must not be negative"))
+	}
+	if _, err := renewal.Fraction(); err != nil {
+		allErrs = append(allErrs, field.Invalid(renewalPath.Child("renewAtFraction"), renewal.RenewAtFraction, err.Error()))
+	}
+
+	if schedule := renewal.Schedule; schedule != nil {
+		schedulePath := renewalPath.Child("schedule")
+		if schedule.TimeZone != "" {
+			if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+				allErrs = append(allErrs, field.Invalid(schedulePath.Child("timeZone"), schedule.TimeZone, err.Error()))
+			}
+		}
+		for i, window := range schedule.Windows {
+			windowPath := schedulePath.Child("windows").Index(i)
+			if _, err := time.Parse("15:04", window.Start); err != nil {
+				allErrs = append(allErrs, field.Invalid(windowPath.Child("start"), window.Start, "must be HH:MM"))
+			}
+			if window.Duration.Duration <= 0 {
+				allErrs = append(allErrs, field.Invalid(windowPath.Child("duration"), window.Duration.String(), "must be positive"))
+			}
+		}
+	}
+
+	for i, target := range spec.RolloutTargets {
+		targetPath := path.Child("rolloutTargets").Index(i)
+		if (target.Name == "") == (target.Selector == nil) {
+			allErrs = append(allErrs, field.Invalid(targetPath, target.Name, "exactly one of name or selector must be set"))
+		}
+		if target.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(target.Selector); err != nil {
+				allErrs = append(allErrs, field.Invalid(targetPath.Child("selector"), target.Selector, err.Error()))
+			}
+		}
+	}
+
+	return allErrs
+}
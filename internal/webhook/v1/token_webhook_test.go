@@ -0,0 +1,462 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	tokenrenewerv1 "github.com/guilhem/token-renewer/api/v1"
+	"github.com/guilhem/token-renewer/internal/providers"
+	"github.com/guilhem/token-renewer/shared"
+)
+
+// mockProvider rejects the metadata when err is set
+type mockProvider struct {
+	err         error
+	calls       int
+	credentials shared.Credentials
+}
+
+func (m *mockProvider) RenewToken(ctx context.Context, req shared.RenewRequest) (shared.RenewResult, error) {
+	return shared.RenewResult{}, errors.New("not implemented")
+}
+
+func (m *mockProvider) GetTokenValidity(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) (*time.Time, error) {
+	m.calls++
+	m.credentials = credentials
+	if m.err != nil {
+		return nil, m.err
+	}
+	exp := time.Now().Add(24 * time.Hour)
+	return &exp, nil
+}
+
+func (m *mockProvider) RevokeToken(ctx context.Context, metadata shared.Metadata, token string, credentials shared.Credentials) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockProvider) RevokePreviousToken(ctx context.Context, previous shared.Metadata, token string, credentials shared.Credentials) error {
+	return errors.New("not implemented")
+}
+
+func newToken() *tokenrenewerv1.Token {
+	return &tokenrenewerv1.Token{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-token", Namespace: "default"},
+		Spec: tokenrenewerv1.TokenSpec{
+			Provider:  tokenrenewerv1.ProviderSpec{Name: "test-provider"},
+			Metadata:  "12345",
+			SecretRef: tokenrenewerv1.SecretReference{Name: "test-secret"},
+		},
+	}
+}
+
+func newValidator(t *testing.T, provider shared.TokenProvider, objs ...client.Object) *TokenCustomValidator {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := tokenrenewerv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("test-token-value")},
+	}
+
+	providersManager := providers.NewProvidersManager()
+	if provider != nil {
+		providersManager.RegisterPlugin("test-provider", provider)
+	}
+
+	return &TokenCustomValidator{
+		Client:           fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, secret)...).Build(),
+		ProvidersManager: providersManager,
+	}
+}
+
+// TestTokenDefaulter tests the defaulted renewal policy and secret key
+func TestTokenDefaulter(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		token := newToken()
+		if err := (&TokenCustomDefaulter{}).Default(context.Background(), token); err != nil {
+			t.Fatal(err)
+		}
+		if token.Spec.Renewal.RenewAtFraction != tokenrenewerv1.DefaultRenewAtFraction {
+			t.Errorf("renewAtFraction = %q, want %q", token.Spec.Renewal.RenewAtFraction, tokenrenewerv1.DefaultRenewAtFraction)
+		}
+		if token.Spec.SecretRef.Key != tokenrenewerv1.DefaultSecretKey {
+			t.Errorf("key = %q, want %q", token.Spec.SecretRef.Key, tokenrenewerv1.DefaultSecretKey)
+		}
+	})
+
+	t.Run("keeps_fraction_policy", func(t *testing.T) {
+		token := newToken()
+		token.Spec.Renewal.RenewAtFraction = "0.5"
+		if err := (&TokenCustomDefaulter{}).Default(context.Background(), token); err != nil {
+			t.Fatal(err)
+		}
+		if token.Spec.Renewal.RenewAtFraction != "0.5" {
+			t.Errorf("renewAtFraction overwritten: %q", token.Spec.Renewal.RenewAtFraction)
+		}
+		if token.Spec.Renewal.BeforeDuration.Duration != 0 {
+			t.Errorf("beforeDuration must not be defaulted with renewAtFraction, got %v", token.Spec.Renewal.BeforeDuration.Duration)
+		}
+	})
+
+	t.Run("keeps_values", func(t *testing.T) {
+		token := newToken()
+		token.Spec.Renewal.BeforeDuration = metav1.Duration{Duration: time.Hour}
+		token.Spec.SecretRef.Key = "api-key"
+		if err := (&TokenCustomDefaulter{}).Default(context.Background(), token); err != nil {
+			t.Fatal(err)
+		}
+		if token.Spec.Renewal.BeforeDuration.Duration != time.Hour || token.Spec.Renewal.RenewAtFraction != "" || token.Spec.SecretRef.Key != "api-key" {
+			t.Errorf("values overwritten: %+v", token.Spec)
+		}
+	})
+}
+
+// TestTokenValidatorSpec tests the rejection of invalid specs
+func TestTokenValidatorSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*tokenrenewerv1.Token)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(*tokenrenewerv1.Token) {},
+		},
+		{
+			name:    "empty_provider",
+			mutate:  func(token *tokenrenewerv1.Token) { token.Spec.Provider.Name = "" },
+			wantErr: true,
+		},
+		{
+			name: "negative_before_duration",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.Renewal.BeforeDuration = metav1.Duration{Duration: -time.Hour}
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative_check_interval",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.Renewal.CheckInterval = &metav1.Duration{Duration: -time.Minute}
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative_overlap",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.Renewal.Overlap = metav1.Duration{Duration: -time.Minute}
+			},
+			wantErr: true,
+		},
+		{
+			name: "rollout_target_name_and_selector",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.RolloutTargets = []tokenrenewerv1.RolloutTarget{{
+					Kind:     tokenrenewerv1.RolloutKindDeployment,
+					Name:     "web",
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid_rollout_target_selector",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.RolloutTargets = []tokenrenewerv1.RolloutTarget{{
+					Kind: tokenrenewerv1.RolloutKindDeployment,
+					Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "app", Operator: "Unknown"},
+					}},
+				}}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid_fraction",
+			mutate:  func(token *tokenrenewerv1.Token) { token.Spec.Renewal.RenewAtFraction = "1.5" },
+			wantErr: true,
+		},
+		{
+			name: "invalid_time_zone",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.Renewal.Schedule = &tokenrenewerv1.RenewalSchedule{
+					TimeZone: "Mars/Olympus",
+					Windows: []tokenrenewerv1.MaintenanceWindow{{
+						Start:    "22:00",
+						Duration: metav1.Duration{Duration: time.Hour},
+					}},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing_secret",
+			mutate:  func(token *tokenrenewerv1.Token) { token.Spec.SecretRef.Name = "missing" },
+			wantErr: true,
+		},
+		{
+			name: "missing_secret_with_bootstrap",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.SecretRef.Name = "missing"
+				token.Spec.BootstrapSecretRef = &tokenrenewerv1.BootstrapSecretReference{Name: "test-secret"}
+			},
+		},
+		{
+			name: "missing_bootstrap_secret",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.SecretRef.Name = "missing"
+				token.Spec.BootstrapSecretRef = &tokenrenewerv1.BootstrapSecretReference{Name: "missing-bootstrap"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "owner_reference_across_namespaces",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.SecretRef.Namespace = "apps"
+				token.Spec.SecretRef.SetOwnerReference = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "rollout_targets_across_namespaces",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.SecretRef.Namespace = "apps"
+				token.Spec.RolloutTargets = []tokenrenewerv1.RolloutTarget{{
+					Kind: tokenrenewerv1.RolloutKindDeployment,
+					Name: "web",
+				}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing_credentials_secret",
+			mutate: func(token *tokenrenewerv1.Token) {
+				token.Spec.CredentialsSecretRef = &tokenrenewerv1.CredentialsSecretReference{Name: "missing-credentials"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := newToken()
+			tt.mutate(token)
+
+			_, err := newValidator(t, nil).ValidateCreate(context.Background(), token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTokenValidatorProvider tests the metadata validation by the provider
+func TestTokenValidatorProvider(t *testing.T) {
+	t.Run("not_connected", func(t *testing.T) {
+		warnings, err := newValidator(t, nil).ValidateCreate(context.Background(), newToken())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(warnings) == 0 {
+			t.Error("expected a warning when the provider is not connected")
+		}
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		provider := &mockProvider{}
+		warnings, err := newValidator(t, provider).ValidateCreate(context.Background(), newToken())
+		if err != nil || len(warnings) > 0 {
+			t.Fatalf("unexpected result: warnings=%v err=%v", warnings, err)
+		}
+		if provider.calls != 1 {
+			t.Errorf("provider called %d times, want 1", provider.calls)
+		}
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		credentials := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("admin-token")},
+		}
+		token := newToken()
+		token.Spec.CredentialsSecretRef = &tokenrenewerv1.CredentialsSecretReference{Name: "test-credentials"}
+
+		provider := &mockProvider{}
+		if _, err := newValidator(t, provider, credentials).ValidateCreate(context.Background(), token); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if provider.credentials["token"] != "admin-token" {
+			t.Errorf("credentials = %v, want the credentials secret entries", provider.credentials)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		provider := &mockProvider{err: errors.New("token 12345 not found")}
+		if _, err := newValidator(t, provider).ValidateCreate(context.Background(), newToken()); err == nil {
+			t.Error("expected the provider rejection to fail the validation")
+		}
+	})
+
+	t.Run("update_without_metadata_change", func(t *testing.T) {
+		provider := &mockProvider{err: errors.New("provider unavailable")}
+		oldToken := newToken()
+		token := newToken()
+		token.Spec.Renewal.BeforeDuration = metav1.Duration{Duration: time.Hour}
+
+		if _, err := newValidator(t, provider).ValidateUpdate(context.Background(), oldToken, token); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if provider.calls != 0 {
+			t.Errorf("provider called %d times, want 0", provider.calls)
+		}
+	})
+}
+
+// TestTokenValidatorSecretGrant tests that a Secret of another namespace is
+// only checked once a TokenSecretGrant allows the reference
+func TestTokenValidatorSecretGrant(t *testing.T) {
+	appSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-secret", Namespace: "apps"},
+		Data:       map[string][]byte{"token": []byte("test-token-value")},
+	}
+	grant := &tokenrenewerv1.TokenSecretGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-default", Namespace: "apps"},
+		Spec: tokenrenewerv1.TokenSecretGrantSpec{
+			From: []tokenrenewerv1.TokenSecretGrantFrom{{Namespace: "default"}},
+		},
+	}
+	crossNamespaceToken := func(name string) *tokenrenewerv1.Token {
+		token := newToken()
+		token.Spec.SecretRef = tokenrenewerv1.SecretReference{Name: name, Namespace: "apps"}
+		return token
+	}
+
+	t.Run("not_granted", func(t *testing.T) {
+		provider := &mockProvider{}
+		warnings, err := newValidator(t, provider, appSecret).ValidateCreate(context.Background(), crossNamespaceToken("app-secret"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(warnings) == 0 {
+			t.Error("expected a warning when the reference is not granted")
+		}
+		if provider.calls != 0 {
+			t.Errorf("provider called %d times, want 0", provider.calls)
+		}
+	})
+
+	t.Run("granted", func(t *testing.T) {
+		provider := &mockProvider{}
+		warnings, err := newValidator(t, provider, appSecret, grant).ValidateCreate(context.Background(), crossNamespaceToken("app-secret"))
+		if err != nil || len(warnings) > 0 {
+			t.Fatalf("unexpected result: warnings=%v err=%v", warnings, err)
+		}
+		if provider.calls != 1 {
+			t.Errorf("provider called %d times, want 1", provider.calls)
+		}
+	})
+
+	t.Run("granted_missing_secret", func(t *testing.T) {
+		if _, err := newValidator(t, nil, grant).ValidateCreate(context.Background(), crossNamespaceToken("missing")); err == nil {
+			t.Error("expected a missing Secret to fail the validation")
+		}
+	})
+}
+
+// TestTokenValidatorUpdate tests that updates only check the Secrets when their
+// references change, so that a Token whose Secrets are gone can be finalized
+func TestTokenValidatorUpdate(t *testing.T) {
+	// withoutSecret returns a validator whose token Secret has been deleted
+	withoutSecret := func(t *testing.T, provider shared.TokenProvider) *TokenCustomValidator {
+		v := newValidator(t, provider)
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "default"}}
+		if err := v.Client.(client.Client).Delete(context.Background(), secret); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	t.Run("finalizer_removal_on_deletion", func(t *testing.T) {
+		now := metav1.Now()
+		oldToken := newToken()
+		oldToken.Spec.CredentialsSecretRef = &tokenrenewerv1.CredentialsSecretReference{Name: "missing-credentials"}
+		oldToken.DeletionTimestamp = &now
+		oldToken.Finalizers = []string{tokenrenewerv1.FinalizerRevoke}
+		token := oldToken.DeepCopy()
+		token.Finalizers = nil
+
+		if _, err := withoutSecret(t, nil).ValidateUpdate(context.Background(), oldToken, token); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("metadata_only", func(t *testing.T) {
+		oldToken := newToken()
+		token := newToken()
+		token.Labels = map[string]string{"team": "platform"}
+
+		if _, err := withoutSecret(t, nil).ValidateUpdate(context.Background(), oldToken, token); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("spec_change_with_missing_secret", func(t *testing.T) {
+		provider := &mockProvider{}
+		oldToken := newToken()
+		token := newToken()
+		token.Spec.Metadata = "67890"
+
+		warnings, err := withoutSecret(t, provider).ValidateUpdate(context.Background(), oldToken, token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(warnings) == 0 {
+			t.Error("expected a warning when the metadata cannot be validated")
+		}
+		if provider.calls != 0 {
+			t.Errorf("provider called %d times, want 0", provider.calls)
+		}
+	})
+
+	t.Run("secret_ref_changed", func(t *testing.T) {
+		oldToken := newToken()
+		token := newToken()
+		token.Spec.SecretRef.Name = "missing"
+
+		if _, err := newValidator(t, nil).ValidateUpdate(context.Background(), oldToken, token); err == nil {
+			t.Error("expected a missing Secret to fail the validation")
+		}
+	})
+}
@@ -3,41 +3,87 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/linode/linodego"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/guilhem/token-renewer/shared"
+	"github.com/guilhem/token-renewer/shared/tracing"
 )
 
 // LinodePlugin implements the TokenProvider interface for Linode API tokens.
 // It uses the Linode API to create, retrieve, and delete tokens.
 type LinodePlugin struct {
 	shared.UnimplementedTokenProviderServiceServer
+
+	// mu serializes the renewals, so that concurrent retries with the same
+	// idempotency key cannot each create a token.
+	mu sync.Mutex
+	// minted holds the tokens created per idempotency key, since the Linode
+	// API only discloses a token value when it is created. It is only kept in
+	// memory: a retry after the plugin restarted creates another token.
+	minted map[string]*mintedToken
+}
+
+// mintedToken is a token created for a renewal, returned again when the
+// renewal is retried with the same idempotency key.
+type mintedToken struct {
+	token           string
+	metadata        shared.Metadata
+	expiration      time.Time
+	previousDeleted bool
 }
 
 // Ensure LinodePlugin implements shared.TokenProviderServiceServer interface
 var _ shared.TokenProviderServiceServer = (*LinodePlugin)(nil)
 
+// metadataFieldID is the structured metadata field holding the Linode token ID.
+const metadataFieldID = "id"
+
+// credentialsKeyToken is the credentials entry holding a Linode API token used
+// instead of the managed token to call the Linode API.
+const credentialsKeyToken = "token"
+
 // RenewToken implements TokenProviderServiceServer.RenewToken.
-func (p *LinodePlugin) RenewToken(ctx context.Context, req *shared.RenewTokenRequest) (*shared.RenewTokenResponse, error) {
-	token, newMetadata, expiration, err := p.renewToken(ctx, req.GetMetadata(), req.GetToken())
+func (p *LinodePlugin) RenewToken(ctx context.Context, req *shared.RenewTokenRequest) (_ *shared.RenewTokenResponse, err error) {
+	ctx, span := startSpan(ctx, "RenewToken", req.GetTraceContext())
+	defer func() { tracing.End(span, err) }()
+
+	renewed, err := p.renewToken(ctx, shared.RenewRequest{
+		Metadata:       shared.Metadata{Value: req.GetMetadata(), Fields: req.GetMetadataFields()},
+		Token:          req.GetToken(),
+		Credentials:    req.GetCredentials(),
+		RetainPrevious: req.GetRetainPrevious(),
+		IdempotencyKey: req.GetIdempotencyKey(),
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &shared.RenewTokenResponse{
-		Token:       token,
-		NewMetadata: newMetadata,
-		Expiration:  timestamppb.New(*expiration),
+		Token:                 renewed.Token,
+		NewMetadata:           renewed.Metadata.Value,
+		NewMetadataFields:     renewed.Metadata.Fields,
+		Expiration:            timestamppb.New(*renewed.Expiration),
+		PreviousTokenRetained: renewed.PreviousRetained,
 	}, nil
 }
 
 // GetTokenValidity implements TokenProviderServiceServer.GetTokenValidity.
-func (p *LinodePlugin) GetTokenValidity(ctx context.Context, req *shared.GetTokenValidityRequest) (*shared.GetTokenValidityResponse, error) {
-	expiration, err := p.getTokenValidity(ctx, req.GetMetadata(), req.GetToken())
+func (p *LinodePlugin) GetTokenValidity(ctx context.Context, req *shared.GetTokenValidityRequest) (_ *shared.GetTokenValidityResponse, err error) {
+	ctx, span := startSpan(ctx, "GetTokenValidity", req.GetTraceContext())
+	defer func() { tracing.End(span, err) }()
+
+	meta := shared.Metadata{Value: req.GetMetadata(), Fields: req.GetMetadataFields()}
+
+	expiration, err := p.getTokenValidity(ctx, meta, req.GetToken(), req.GetCredentials())
 	if err != nil {
 		return nil, err
 	}
@@ -47,52 +93,141 @@ func (p *LinodePlugin) GetTokenValidity(ctx context.Context, req *shared.GetToke
 	}, nil
 }
 
-// renewToken is the internal implementation for token renewal.
-func (p *LinodePlugin) renewToken(ctx context.Context, meta, token string) (string, string, *time.Time, error) {
-	id, err := p.metadataToID(meta)
-	if err != nil {
-		return "", "", nil, fmt.Errorf("invalid metadata: %w", err)
+// RevokeToken implements TokenProviderServiceServer.RevokeToken.
+func (p *LinodePlugin) RevokeToken(ctx context.Context, req *shared.RevokeTokenRequest) (_ *shared.RevokeTokenResponse, err error) {
+	ctx, span := startSpan(ctx, "RevokeToken", req.GetTraceContext())
+	defer func() { tracing.End(span, err) }()
+
+	meta := shared.Metadata{Value: req.GetMetadata(), Fields: req.GetMetadataFields()}
+
+	if err := p.revokeToken(ctx, meta, req.GetToken(), req.GetCredentials()); err != nil {
+		return nil, err
 	}
 
-	cl := linodego.NewClient(nil)
-	cl.SetToken(token)
+	return &shared.RevokeTokenResponse{}, nil
+}
 
-	oldToken, err := cl.GetToken(ctx, id)
-	if err != nil {
-		return "", "", nil, fmt.Errorf("failed to get token: %w", err)
+// RevokePreviousToken implements TokenProviderServiceServer.RevokePreviousToken.
+// It deletes the previous token retained by a two-phase rotation, identified by
+// the request metadata; a token that is already gone counts as revoked.
+func (p *LinodePlugin) RevokePreviousToken(ctx context.Context, req *shared.RevokePreviousTokenRequest) (_ *shared.RevokePreviousTokenResponse, err error) {
+	ctx, span := startSpan(ctx, "RevokePreviousToken", req.GetTraceContext())
+	defer func() { tracing.End(span, err) }()
+
+	meta := shared.Metadata{Value: req.GetMetadata(), Fields: req.GetMetadataFields()}
+
+	if err := p.revokeToken(ctx, meta, req.GetToken(), req.GetCredentials()); err != nil {
+		return nil, err
 	}
 
-	expireTime := time.Now().Add(24 * time.Hour)
+	return &shared.RevokePreviousTokenResponse{}, nil
+}
 
-	newToken, err := cl.CreateToken(ctx, linodego.TokenCreateOptions{
-		Label:  oldToken.Label,
-		Scopes: oldToken.Scopes,
-		Expiry: &expireTime,
-	})
+// startSpan starts the span of an RPC, continuing the trace of the controller
+// carried by the request.
+func startSpan(ctx context.Context, method string, traceContext map[string]string) (context.Context, trace.Span) {
+	ctx = shared.ExtractTraceContext(ctx, traceContext)
+	return tracing.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// renewToken is the internal implementation for token renewal. With
+// RetainPrevious, the old token is left for RevokePreviousToken to delete. A
+// retry with the idempotency key of a renewal that already created a token
+// returns that token, only deleting the old one if that step failed.
+func (p *LinodePlugin) renewToken(ctx context.Context, req shared.RenewRequest) (shared.RenewResult, error) {
+	id, err := p.tokenID(req.Metadata)
 	if err != nil {
-		return "", "", nil, fmt.Errorf("failed to create token: %w", err)
+		return shared.RenewResult{}, fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	cl := p.client(req.Token, req.Credentials)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	minted := p.mintedToken(req.IdempotencyKey)
+	if minted == nil {
+		oldToken, err := cl.GetToken(ctx, id)
+		if err != nil {
+			return shared.RenewResult{}, fmt.Errorf("failed to get token: %w", err)
+		}
+
+		expireTime := time.Now().Add(24 * time.Hour)
+
+		newToken, err := cl.CreateToken(ctx, linodego.TokenCreateOptions{
+			Label:  oldToken.Label,
+			Scopes: oldToken.Scopes,
+			Expiry: &expireTime,
+		})
+		if err != nil {
+			return shared.RenewResult{}, fmt.Errorf("failed to create token: %w", err)
+		}
+
+		minted = &mintedToken{
+			token:      newToken.Token,
+			metadata:   p.newMetadata(req.Metadata, newToken.ID),
+			expiration: expireTime,
+		}
+		p.storeMintedToken(req.IdempotencyKey, minted)
+	}
+
+	if !req.RetainPrevious && !minted.previousDeleted {
+		if err := cl.DeleteToken(ctx, id); err != nil && !linodego.IsNotFound(err) {
+			return shared.RenewResult{}, fmt.Errorf("failed to delete old token: %w", err)
+		}
+		minted.previousDeleted = true
 	}
 
-	if err := cl.DeleteToken(ctx, id); err != nil {
-		return "", "", nil, fmt.Errorf("failed to delete old token: %w", err)
+	expiration := minted.expiration
+	return shared.RenewResult{
+		Token:            minted.token,
+		Metadata:         minted.metadata,
+		Expiration:       &expiration,
+		PreviousRetained: req.RetainPrevious,
+	}, nil
+}
+
+// mintedToken returns the token created for the idempotency key, or nil. The
+// tokens that have expired are forgotten. p.mu must be held.
+func (p *LinodePlugin) mintedToken(idempotencyKey string) *mintedToken {
+	if idempotencyKey == "" {
+		return nil
 	}
 
-	return newToken.Token, strconv.Itoa(newToken.ID), &expireTime, nil
+	now := time.Now()
+	for key, minted := range p.minted {
+		if !minted.expiration.After(now) {
+			delete(p.minted, key)
+		}
+	}
+	return p.minted[idempotencyKey]
+}
+
+// storeMintedToken records the token created for the idempotency key. p.mu
+// must be held.
+func (p *LinodePlugin) storeMintedToken(idempotencyKey string, minted *mintedToken) {
+	if idempotencyKey == "" {
+		return
+	}
+
+	if p.minted == nil {
+		p.minted = make(map[string]*mintedToken)
+	}
+	p.minted[idempotencyKey] = minted
 }
 
 // getTokenValidity is the internal implementation for validity check.
-func (p *LinodePlugin) getTokenValidity(ctx context.Context, meta, token string) (*time.Time, error) {
-	id, err := p.metadataToID(meta)
+func (p *LinodePlugin) getTokenValidity(ctx context.Context, meta shared.Metadata, token string, credentials map[string]string) (*time.Time, error) {
+	id, err := p.tokenID(meta)
 	if err != nil {
 		return nil, fmt.Errorf("invalid metadata: %w", err)
 	}
 
-	cl := linodego.NewClient(nil)
-	cl.SetToken(token)
+	cl := p.client(token, credentials)
 
 	oldToken, err := cl.GetToken(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token: %w", err)
+		return nil, tokenValidityError(err, credentials)
 	}
 
 	if oldToken.Expiry != nil {
@@ -105,6 +240,72 @@ func (p *LinodePlugin) getTokenValidity(ctx context.Context, meta, token string)
 	return &futureTime, nil
 }
 
+// tokenValidityError reports with a gRPC status a token Linode no longer
+// knows, or rejects when it authenticates its own lookup; other errors, such
+// as timeouts or server errors, say nothing about the token.
+func tokenValidityError(err error, credentials map[string]string) error {
+	switch {
+	case linodego.IsNotFound(err):
+		return status.Errorf(codes.NotFound, "token not found: %v", err)
+	case credentials[credentialsKeyToken] == "" && linodego.ErrHasStatus(err, http.StatusUnauthorized):
+		return status.Errorf(codes.Unauthenticated, "token rejected: %v", err)
+	default:
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+}
+
+// revokeToken is the internal implementation for token revocation. A token
+// that no longer exists is considered revoked.
+func (p *LinodePlugin) revokeToken(ctx context.Context, meta shared.Metadata, token string, credentials map[string]string) error {
+	id, err := p.tokenID(meta)
+	if err != nil {
+		return fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	cl := p.client(token, credentials)
+
+	if err := cl.DeleteToken(ctx, id); err != nil && !linodego.IsNotFound(err) {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+
+	return nil
+}
+
+// client returns a Linode API client authenticated with the credentials token
+// when set, or with the managed token itself.
+func (p *LinodePlugin) client(token string, credentials map[string]string) *linodego.Client {
+	if apiToken := credentials[credentialsKeyToken]; apiToken != "" {
+		token = apiToken
+	}
+
+	cl := linodego.NewClient(nil)
+	cl.SetToken(token)
+	return &cl
+}
+
 func (p *LinodePlugin) metadataToID(meta string) (int, error) {
 	return strconv.Atoi(meta)
 }
+
+// tokenID returns the Linode token ID from the "id" metadata field or, for
+// compatibility, from the opaque metadata string.
+func (p *LinodePlugin) tokenID(meta shared.Metadata) (int, error) {
+	if id, ok := meta.Fields[metadataFieldID]; ok {
+		return p.metadataToID(id)
+	}
+	return p.metadataToID(meta.Value)
+}
+
+// newMetadata returns the metadata of a newly created token. Structured
+// metadata is only returned when the request used it, with the ID replaced.
+func (p *LinodePlugin) newMetadata(meta shared.Metadata, id int) shared.Metadata {
+	newMeta := shared.Metadata{Value: strconv.Itoa(id)}
+	if len(meta.Fields) > 0 {
+		newMeta.Fields = make(map[string]string, len(meta.Fields))
+		for k, v := range meta.Fields {
+			newMeta.Fields[k] = v
+		}
+		newMeta.Fields[metadataFieldID] = newMeta.Value
+	}
+	return newMeta
+}
@@ -2,7 +2,13 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/guilhem/token-renewer/shared"
 )
@@ -60,6 +66,61 @@ func TestRenewToken_Signature(t *testing.T) {
 	t.Logf("Test result: error=%v (expected in test env)", err)
 }
 
+// TestRevokeToken_InvalidMetadata tests that invalid metadata is rejected
+// before calling the Linode API
+func TestRevokeToken_InvalidMetadata(t *testing.T) {
+	plugin := &LinodePlugin{}
+
+	req := &shared.RevokeTokenRequest{
+		Metadata: "not-an-id",
+		Token:    "dummy-token",
+	}
+
+	if _, err := plugin.RevokeToken(context.Background(), req); err == nil {
+		t.Error("expected an error for non-numeric metadata")
+	}
+}
+
+// TestTokenValidityError tests that only the Linode API reporting the token
+// unknown or rejected is reported as an invalid token
+func TestTokenValidityError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		credentials map[string]string
+		want        codes.Code
+	}{
+		{"not_found", &linodego.Error{Code: http.StatusNotFound}, nil, codes.NotFound},
+		{"unauthorized", &linodego.Error{Code: http.StatusUnauthorized}, nil, codes.Unauthenticated},
+		{"unauthorized_credentials", &linodego.Error{Code: http.StatusUnauthorized}, map[string]string{credentialsKeyToken: "api-token"}, codes.Unknown},
+		{"server_error", &linodego.Error{Code: http.StatusServiceUnavailable}, nil, codes.Unknown},
+		{"timeout", context.DeadlineExceeded, nil, codes.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tokenValidityError(tt.err, tt.credentials)); got != tt.want {
+				t.Errorf("tokenValidityError() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRevokePreviousToken_InvalidMetadata tests that invalid previous token
+// metadata is rejected before calling the Linode API
+func TestRevokePreviousToken_InvalidMetadata(t *testing.T) {
+	plugin := &LinodePlugin{}
+
+	req := &shared.RevokePreviousTokenRequest{
+		Metadata: "not-an-id",
+		Token:    "dummy-token",
+	}
+
+	if _, err := plugin.RevokePreviousToken(context.Background(), req); err == nil {
+		t.Error("expected an error for non-numeric metadata")
+	}
+}
+
 // TestLinodePlugin_MetadataConversion tests metadata to ID conversion
 func TestLinodePlugin_MetadataConversion(t *testing.T) {
 	plugin := &LinodePlugin{}
@@ -88,3 +149,84 @@ func TestLinodePlugin_MetadataConversion(t *testing.T) {
 		})
 	}
 }
+
+// TestLinodePlugin_TokenID tests reading the token ID from structured and legacy metadata
+func TestLinodePlugin_TokenID(t *testing.T) {
+	plugin := &LinodePlugin{}
+
+	tests := []struct {
+		name    string
+		meta    shared.Metadata
+		want    int
+		wantErr bool
+	}{
+		{"legacy_string", shared.Metadata{Value: "12345"}, 12345, false},
+		{"structured_id", shared.Metadata{Fields: map[string]string{"id": "678"}}, 678, false},
+		{"structured_takes_precedence", shared.Metadata{Value: "12345", Fields: map[string]string{"id": "678"}}, 678, false},
+		{"structured_without_id", shared.Metadata{Fields: map[string]string{"label": "ci"}}, 0, true},
+		{"invalid_structured_id", shared.Metadata{Fields: map[string]string{"id": "abc"}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := plugin.tokenID(tt.meta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("tokenID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLinodePlugin_NewMetadata tests the metadata returned for a new token
+func TestLinodePlugin_NewMetadata(t *testing.T) {
+	plugin := &LinodePlugin{}
+
+	legacy := plugin.newMetadata(shared.Metadata{Value: "1"}, 2)
+	if legacy.Value != "2" || legacy.Fields != nil {
+		t.Errorf("legacy metadata = %+v, want Value 2 and no fields", legacy)
+	}
+
+	fields := map[string]string{"id": "1", "label": "ci"}
+	structured := plugin.newMetadata(shared.Metadata{Fields: fields}, 2)
+	if structured.Fields["id"] != "2" || structured.Fields["label"] != "ci" {
+		t.Errorf("structured metadata = %+v, want id 2 and label ci", structured.Fields)
+	}
+	if fields["id"] != "1" {
+		t.Error("request metadata must not be modified")
+	}
+}
+
+// TestRenewToken_IdempotencyKey tests that a retried renewal returns the token
+// already created for its idempotency key without calling the Linode API
+func TestRenewToken_IdempotencyKey(t *testing.T) {
+	plugin := &LinodePlugin{}
+	plugin.storeMintedToken("uid/1", &mintedToken{
+		token:           "minted-token",
+		metadata:        shared.Metadata{Value: "2"},
+		expiration:      time.Now().Add(time.Hour),
+		previousDeleted: true,
+	})
+	plugin.storeMintedToken("uid/0", &mintedToken{
+		token:      "expired-token",
+		expiration: time.Now().Add(-time.Hour),
+	})
+
+	resp, err := plugin.RenewToken(context.Background(), &shared.RenewTokenRequest{
+		Metadata:       "1",
+		Token:          "old-token",
+		IdempotencyKey: "uid/1",
+	})
+	if err != nil {
+		t.Fatalf("RenewToken() error = %v", err)
+	}
+	if resp.GetToken() != "minted-token" || resp.GetNewMetadata() != "2" {
+		t.Errorf("RenewToken() = %q/%q, want minted-token/2", resp.GetToken(), resp.GetNewMetadata())
+	}
+
+	if plugin.mintedToken("uid/0") != nil {
+		t.Error("expired minted token must be forgotten")
+	}
+}
@@ -9,7 +9,11 @@ import (
 	"syscall"
 
 	"github.com/guilhem/operator-plugin-framework/client"
+	pluginframeworkv1 "github.com/guilhem/operator-plugin-framework/pluginframework/v1"
+	"github.com/guilhem/operator-plugin-framework/stream"
 	"github.com/guilhem/token-renewer/shared"
+	"github.com/guilhem/token-renewer/shared/tracing"
+	"google.golang.org/grpc"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -23,6 +27,7 @@ func main() {
 	var (
 		operatorAddr    string
 		useServiceToken bool
+		tracingOpts     tracing.Options
 	)
 
 	flag.StringVar(&operatorAddr, "operator-addr", "https://operator-kube-rbac-proxy:8443",
@@ -34,6 +39,7 @@ func main() {
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
+	tracingOpts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	logger := zap.New(zap.UseFlagOptions(&opts))
@@ -51,6 +57,17 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	shutdownTracing, err := tracing.Setup(ctx, "token-renewer-"+pluginName, tracingOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to flush traces")
+		}
+	}()
+
 	// Create Linode plugin instance
 	linodePlugin := &LinodePlugin{}
 
@@ -77,9 +94,6 @@ func runPlugin(ctx context.Context, operatorAddr string, useServiceToken bool, p
 		logger.Info("Using Kubernetes ServiceAccount token for authentication")
 	}
 
-	// Create Plugin Service implementation
-	pluginServer := &LinodePlugin{}
-
 	// Create PluginStreamClient using the simplified framework API
 	pluginStreamClient, err := client.New(
 		ctx,
@@ -87,7 +101,8 @@ func runPlugin(ctx context.Context, operatorAddr string, useServiceToken bool, p
 		operatorAddr,
 		pluginVersion,
 		shared.TokenProviderService_ServiceDesc,
-		pluginServer,
+		plugin,
+		pluginStream(ctx),
 		clientOpts...,
 	)
 	if err != nil {
@@ -105,3 +120,17 @@ func runPlugin(ctx context.Context, operatorAddr string, useServiceToken bool, p
 	// Start handling RPC calls - this blocks until context is cancelled
 	return pluginStreamClient.HandleRPCCalls(ctx)
 }
+
+// pluginStream returns the function opening the PluginStream RPC of the
+// operator, over which the operator sends the TokenProviderService calls.
+func pluginStream(ctx context.Context) client.StreamCreatorFunc {
+	return func(conn *grpc.ClientConn) (stream.StreamInterface, error) {
+		desc := &grpc.StreamDesc{StreamName: "PluginStream", ServerStreams: true, ClientStreams: true}
+		method := "/" + shared.TokenProviderService_ServiceDesc.ServiceName + "/" + desc.StreamName
+		cs, err := conn.NewStream(ctx, desc, method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open plugin stream: %w", err)
+		}
+		return &grpc.GenericClientStream[pluginframeworkv1.PluginStreamMessage, pluginframeworkv1.PluginStreamMessage]{ClientStream: cs}, nil
+	}
+}
@@ -24,9 +24,27 @@ const (
 
 // RenewTokenRequest is the request message for the RenewToken RPC.
 type RenewTokenRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Metadata      string                 `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
-	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// metadata is the legacy opaque provider metadata.
+	Metadata string `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Token    string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// metadata_fields is the structured provider metadata.
+	MetadataFields map[string]string `protobuf:"bytes,3,rep,name=metadata_fields,json=metadataFields,proto3" json:"metadata_fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// credentials are the values of the Token credentials Secret. When set,
+	// the plugin authenticates with them instead of the token.
+	Credentials map[string]string `protobuf:"bytes,4,rep,name=credentials,proto3" json:"credentials,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// retain_previous asks for a two-phase rotation: the previous token stays
+	// valid until RevokePreviousToken is called.
+	RetainPrevious bool `protobuf:"varint,5,opt,name=retain_previous,json=retainPrevious,proto3" json:"retain_previous,omitempty"`
+	// idempotency_key identifies the rotation this request belongs to. It is
+	// the same for every retry of a rotation, and plugins that received it for
+	// a token they already minted return that token instead of minting another.
+	// Plugins may only remember the keys for a while, for example in memory
+	// until they restart; a retry with a forgotten key mints another token.
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// trace_context carries the W3C trace context of the controller, for
+	// plugins to continue the trace.
+	TraceContext  map[string]string `protobuf:"bytes,7,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -75,14 +93,55 @@ func (x *RenewTokenRequest) GetToken() string {
 	return ""
 }
 
+func (x *RenewTokenRequest) GetMetadataFields() map[string]string {
+	if x != nil {
+		return x.MetadataFields
+	}
+	return nil
+}
+
+func (x *RenewTokenRequest) GetCredentials() map[string]string {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+func (x *RenewTokenRequest) GetRetainPrevious() bool {
+	if x != nil {
+		return x.RetainPrevious
+	}
+	return false
+}
+
+func (x *RenewTokenRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *RenewTokenRequest) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
 // RenewTokenResponse is the response message for the RenewToken RPC.
 type RenewTokenResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	NewMetadata   string                 `protobuf:"bytes,2,opt,name=new_metadata,json=newMetadata,proto3" json:"new_metadata,omitempty"`
-	Expiration    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expiration,proto3" json:"expiration,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Token       string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	NewMetadata string                 `protobuf:"bytes,2,opt,name=new_metadata,json=newMetadata,proto3" json:"new_metadata,omitempty"`
+	Expiration  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expiration,proto3" json:"expiration,omitempty"`
+	// new_metadata_fields is the structured provider metadata of the new token.
+	NewMetadataFields map[string]string `protobuf:"bytes,4,rep,name=new_metadata_fields,json=newMetadataFields,proto3" json:"new_metadata_fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// previous_token_retained reports that the previous token is still valid.
+	// Plugins without two-phase rotation leave it unset and revoke the previous
+	// token during the renewal.
+	PreviousTokenRetained bool `protobuf:"varint,5,opt,name=previous_token_retained,json=previousTokenRetained,proto3" json:"previous_token_retained,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
 }
 
 func (x *RenewTokenResponse) Reset() {
@@ -136,11 +195,34 @@ func (x *RenewTokenResponse) GetExpiration() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *RenewTokenResponse) GetNewMetadataFields() map[string]string {
+	if x != nil {
+		return x.NewMetadataFields
+	}
+	return nil
+}
+
+func (x *RenewTokenResponse) GetPreviousTokenRetained() bool {
+	if x != nil {
+		return x.PreviousTokenRetained
+	}
+	return false
+}
+
 // GetTokenValidityRequest is the request message for the GetTokenValidity RPC.
 type GetTokenValidityRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Metadata      string                 `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
-	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// metadata is the legacy opaque provider metadata.
+	Metadata string `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Token    string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// metadata_fields is the structured provider metadata.
+	MetadataFields map[string]string `protobuf:"bytes,3,rep,name=metadata_fields,json=metadataFields,proto3" json:"metadata_fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// credentials are the values of the Token credentials Secret. When set,
+	// the plugin authenticates with them instead of the token.
+	Credentials map[string]string `protobuf:"bytes,4,rep,name=credentials,proto3" json:"credentials,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// trace_context carries the W3C trace context of the controller, for
+	// plugins to continue the trace.
+	TraceContext  map[string]string `protobuf:"bytes,5,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -189,6 +271,27 @@ func (x *GetTokenValidityRequest) GetToken() string {
 	return ""
 }
 
+func (x *GetTokenValidityRequest) GetMetadataFields() map[string]string {
+	if x != nil {
+		return x.MetadataFields
+	}
+	return nil
+}
+
+func (x *GetTokenValidityRequest) GetCredentials() map[string]string {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+func (x *GetTokenValidityRequest) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
 // GetTokenValidityResponse is the response message for the GetTokenValidity RPC.
 type GetTokenValidityResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -234,31 +337,337 @@ func (x *GetTokenValidityResponse) GetExpiration() *timestamppb.Timestamp {
 	return nil
 }
 
+// RevokeTokenRequest is the request message for the RevokeToken RPC.
+type RevokeTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// metadata is the legacy opaque provider metadata.
+	Metadata string `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Token    string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// metadata_fields is the structured provider metadata.
+	MetadataFields map[string]string `protobuf:"bytes,3,rep,name=metadata_fields,json=metadataFields,proto3" json:"metadata_fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// credentials are the values of the Token credentials Secret. When set,
+	// the plugin authenticates with them instead of the token.
+	Credentials map[string]string `protobuf:"bytes,4,rep,name=credentials,proto3" json:"credentials,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// trace_context carries the W3C trace context of the controller, for
+	// plugins to continue the trace.
+	TraceContext  map[string]string `protobuf:"bytes,5,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeTokenRequest) Reset() {
+	*x = RevokeTokenRequest{}
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeTokenRequest) ProtoMessage() {}
+
+func (x *RevokeTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeTokenRequest.ProtoReflect.Descriptor instead.
+func (*RevokeTokenRequest) Descriptor() ([]byte, []int) {
+	return file_barpilot_token_renewer_v1_token_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RevokeTokenRequest) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+func (x *RevokeTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RevokeTokenRequest) GetMetadataFields() map[string]string {
+	if x != nil {
+		return x.MetadataFields
+	}
+	return nil
+}
+
+func (x *RevokeTokenRequest) GetCredentials() map[string]string {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+func (x *RevokeTokenRequest) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
+// RevokeTokenResponse is the response message for the RevokeToken RPC.
+type RevokeTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeTokenResponse) Reset() {
+	*x = RevokeTokenResponse{}
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeTokenResponse) ProtoMessage() {}
+
+func (x *RevokeTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeTokenResponse.ProtoReflect.Descriptor instead.
+func (*RevokeTokenResponse) Descriptor() ([]byte, []int) {
+	return file_barpilot_token_renewer_v1_token_proto_rawDescGZIP(), []int{5}
+}
+
+// RevokePreviousTokenRequest is the request message for the RevokePreviousToken RPC.
+type RevokePreviousTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// metadata is the legacy opaque provider metadata of the previous token.
+	Metadata string `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// token is the current token.
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// metadata_fields is the structured provider metadata of the previous token.
+	MetadataFields map[string]string `protobuf:"bytes,3,rep,name=metadata_fields,json=metadataFields,proto3" json:"metadata_fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// credentials are the values of the Token credentials Secret. When set,
+	// the plugin authenticates with them instead of the token.
+	Credentials map[string]string `protobuf:"bytes,4,rep,name=credentials,proto3" json:"credentials,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// trace_context carries the W3C trace context of the controller, for
+	// plugins to continue the trace.
+	TraceContext  map[string]string `protobuf:"bytes,5,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokePreviousTokenRequest) Reset() {
+	*x = RevokePreviousTokenRequest{}
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokePreviousTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokePreviousTokenRequest) ProtoMessage() {}
+
+func (x *RevokePreviousTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokePreviousTokenRequest.ProtoReflect.Descriptor instead.
+func (*RevokePreviousTokenRequest) Descriptor() ([]byte, []int) {
+	return file_barpilot_token_renewer_v1_token_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RevokePreviousTokenRequest) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+func (x *RevokePreviousTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RevokePreviousTokenRequest) GetMetadataFields() map[string]string {
+	if x != nil {
+		return x.MetadataFields
+	}
+	return nil
+}
+
+func (x *RevokePreviousTokenRequest) GetCredentials() map[string]string {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+func (x *RevokePreviousTokenRequest) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
+// RevokePreviousTokenResponse is the response message for the RevokePreviousToken RPC.
+type RevokePreviousTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokePreviousTokenResponse) Reset() {
+	*x = RevokePreviousTokenResponse{}
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokePreviousTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokePreviousTokenResponse) ProtoMessage() {}
+
+func (x *RevokePreviousTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_barpilot_token_renewer_v1_token_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokePreviousTokenResponse.ProtoReflect.Descriptor instead.
+func (*RevokePreviousTokenResponse) Descriptor() ([]byte, []int) {
+	return file_barpilot_token_renewer_v1_token_proto_rawDescGZIP(), []int{7}
+}
+
 var File_barpilot_token_renewer_v1_token_proto protoreflect.FileDescriptor
 
 const file_barpilot_token_renewer_v1_token_proto_rawDesc = "" +
 	"\n" +
-	"%barpilot/token_renewer/v1/token.proto\x12\x19barpilot.token_renewer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"E\n" +
+	"%barpilot/token_renewer/v1/token.proto\x12\x19barpilot.token_renewer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8c\x05\n" +
 	"\x11RenewTokenRequest\x12\x1a\n" +
 	"\bmetadata\x18\x01 \x01(\tR\bmetadata\x12\x14\n" +
-	"\x05token\x18\x02 \x01(\tR\x05token\"\x89\x01\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12i\n" +
+	"\x0fmetadata_fields\x18\x03 \x03(\v2@.barpilot.token_renewer.v1.RenewTokenRequest.MetadataFieldsEntryR\x0emetadataFields\x12_\n" +
+	"\vcredentials\x18\x04 \x03(\v2=.barpilot.token_renewer.v1.RenewTokenRequest.CredentialsEntryR\vcredentials\x12'\n" +
+	"\x0fretain_previous\x18\x05 \x01(\bR\x0eretainPrevious\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\x12c\n" +
+	"\rtrace_context\x18\a \x03(\v2>.barpilot.token_renewer.v1.RenewTokenRequest.TraceContextEntryR\ftraceContext\x1aA\n" +
+	"\x13MetadataFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10CredentialsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11TraceContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xfd\x02\n" +
 	"\x12RenewTokenResponse\x12\x14\n" +
 	"\x05token\x18\x01 \x01(\tR\x05token\x12!\n" +
 	"\fnew_metadata\x18\x02 \x01(\tR\vnewMetadata\x12:\n" +
 	"\n" +
 	"expiration\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
-	"expiration\"K\n" +
+	"expiration\x12t\n" +
+	"\x13new_metadata_fields\x18\x04 \x03(\v2D.barpilot.token_renewer.v1.RenewTokenResponse.NewMetadataFieldsEntryR\x11newMetadataFields\x126\n" +
+	"\x17previous_token_retained\x18\x05 \x01(\bR\x15previousTokenRetained\x1aD\n" +
+	"\x16NewMetadataFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd2\x04\n" +
 	"\x17GetTokenValidityRequest\x12\x1a\n" +
 	"\bmetadata\x18\x01 \x01(\tR\bmetadata\x12\x14\n" +
-	"\x05token\x18\x02 \x01(\tR\x05token\"V\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12o\n" +
+	"\x0fmetadata_fields\x18\x03 \x03(\v2F.barpilot.token_renewer.v1.GetTokenValidityRequest.MetadataFieldsEntryR\x0emetadataFields\x12e\n" +
+	"\vcredentials\x18\x04 \x03(\v2C.barpilot.token_renewer.v1.GetTokenValidityRequest.CredentialsEntryR\vcredentials\x12i\n" +
+	"\rtrace_context\x18\x05 \x03(\v2D.barpilot.token_renewer.v1.GetTokenValidityRequest.TraceContextEntryR\ftraceContext\x1aA\n" +
+	"\x13MetadataFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10CredentialsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11TraceContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"V\n" +
 	"\x18GetTokenValidityResponse\x12:\n" +
 	"\n" +
 	"expiration\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
-	"expiration2\xfe\x01\n" +
+	"expiration\"\xbe\x04\n" +
+	"\x12RevokeTokenRequest\x12\x1a\n" +
+	"\bmetadata\x18\x01 \x01(\tR\bmetadata\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12j\n" +
+	"\x0fmetadata_fields\x18\x03 \x03(\v2A.barpilot.token_renewer.v1.RevokeTokenRequest.MetadataFieldsEntryR\x0emetadataFields\x12`\n" +
+	"\vcredentials\x18\x04 \x03(\v2>.barpilot.token_renewer.v1.RevokeTokenRequest.CredentialsEntryR\vcredentials\x12d\n" +
+	"\rtrace_context\x18\x05 \x03(\v2?.barpilot.token_renewer.v1.RevokeTokenRequest.TraceContextEntryR\ftraceContext\x1aA\n" +
+	"\x13MetadataFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10CredentialsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11TraceContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x15\n" +
+	"\x13RevokeTokenResponse\"\xde\x04\n" +
+	"\x1aRevokePreviousTokenRequest\x12\x1a\n" +
+	"\bmetadata\x18\x01 \x01(\tR\bmetadata\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12r\n" +
+	"\x0fmetadata_fields\x18\x03 \x03(\v2I.barpilot.token_renewer.v1.RevokePreviousTokenRequest.MetadataFieldsEntryR\x0emetadataFields\x12h\n" +
+	"\vcredentials\x18\x04 \x03(\v2F.barpilot.token_renewer.v1.RevokePreviousTokenRequest.CredentialsEntryR\vcredentials\x12l\n" +
+	"\rtrace_context\x18\x05 \x03(\v2G.barpilot.token_renewer.v1.RevokePreviousTokenRequest.TraceContextEntryR\ftraceContext\x1aA\n" +
+	"\x13MetadataFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10CredentialsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11TraceContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x1d\n" +
+	"\x1bRevokePreviousTokenResponse2\xf3\x03\n" +
 	"\x14TokenProviderService\x12i\n" +
 	"\n" +
 	"RenewToken\x12,.barpilot.token_renewer.v1.RenewTokenRequest\x1a-.barpilot.token_renewer.v1.RenewTokenResponse\x12{\n" +
-	"\x10GetTokenValidity\x122.barpilot.token_renewer.v1.GetTokenValidityRequest\x1a3.barpilot.token_renewer.v1.GetTokenValidityResponseB\n" +
+	"\x10GetTokenValidity\x122.barpilot.token_renewer.v1.GetTokenValidityRequest\x1a3.barpilot.token_renewer.v1.GetTokenValidityResponse\x12l\n" +
+	"\vRevokeToken\x12-.barpilot.token_renewer.v1.RevokeTokenRequest\x1a..barpilot.token_renewer.v1.RevokeTokenResponse\x12\x84\x01\n" +
+	"\x13RevokePreviousToken\x125.barpilot.token_renewer.v1.RevokePreviousTokenRequest\x1a6.barpilot.token_renewer.v1.RevokePreviousTokenResponseB\n" +
 	"Z\b./sharedb\x06proto3"
 
 var (
@@ -273,26 +682,60 @@ func file_barpilot_token_renewer_v1_token_proto_rawDescGZIP() []byte {
 	return file_barpilot_token_renewer_v1_token_proto_rawDescData
 }
 
-var file_barpilot_token_renewer_v1_token_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_barpilot_token_renewer_v1_token_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_barpilot_token_renewer_v1_token_proto_goTypes = []any{
-	(*RenewTokenRequest)(nil),        // 0: barpilot.token_renewer.v1.RenewTokenRequest
-	(*RenewTokenResponse)(nil),       // 1: barpilot.token_renewer.v1.RenewTokenResponse
-	(*GetTokenValidityRequest)(nil),  // 2: barpilot.token_renewer.v1.GetTokenValidityRequest
-	(*GetTokenValidityResponse)(nil), // 3: barpilot.token_renewer.v1.GetTokenValidityResponse
-	(*timestamppb.Timestamp)(nil),    // 4: google.protobuf.Timestamp
+	(*RenewTokenRequest)(nil),           // 0: barpilot.token_renewer.v1.RenewTokenRequest
+	(*RenewTokenResponse)(nil),          // 1: barpilot.token_renewer.v1.RenewTokenResponse
+	(*GetTokenValidityRequest)(nil),     // 2: barpilot.token_renewer.v1.GetTokenValidityRequest
+	(*GetTokenValidityResponse)(nil),    // 3: barpilot.token_renewer.v1.GetTokenValidityResponse
+	(*RevokeTokenRequest)(nil),          // 4: barpilot.token_renewer.v1.RevokeTokenRequest
+	(*RevokeTokenResponse)(nil),         // 5: barpilot.token_renewer.v1.RevokeTokenResponse
+	(*RevokePreviousTokenRequest)(nil),  // 6: barpilot.token_renewer.v1.RevokePreviousTokenRequest
+	(*RevokePreviousTokenResponse)(nil), // 7: barpilot.token_renewer.v1.RevokePreviousTokenResponse
+	nil,                                 // 8: barpilot.token_renewer.v1.RenewTokenRequest.MetadataFieldsEntry
+	nil,                                 // 9: barpilot.token_renewer.v1.RenewTokenRequest.CredentialsEntry
+	nil,                                 // 10: barpilot.token_renewer.v1.RenewTokenRequest.TraceContextEntry
+	nil,                                 // 11: barpilot.token_renewer.v1.RenewTokenResponse.NewMetadataFieldsEntry
+	nil,                                 // 12: barpilot.token_renewer.v1.GetTokenValidityRequest.MetadataFieldsEntry
+	nil,                                 // 13: barpilot.token_renewer.v1.GetTokenValidityRequest.CredentialsEntry
+	nil,                                 // 14: barpilot.token_renewer.v1.GetTokenValidityRequest.TraceContextEntry
+	nil,                                 // 15: barpilot.token_renewer.v1.RevokeTokenRequest.MetadataFieldsEntry
+	nil,                                 // 16: barpilot.token_renewer.v1.RevokeTokenRequest.CredentialsEntry
+	nil,                                 // 17: barpilot.token_renewer.v1.RevokeTokenRequest.TraceContextEntry
+	nil,                                 // 18: barpilot.token_renewer.v1.RevokePreviousTokenRequest.MetadataFieldsEntry
+	nil,                                 // 19: barpilot.token_renewer.v1.RevokePreviousTokenRequest.CredentialsEntry
+	nil,                                 // 20: barpilot.token_renewer.v1.RevokePreviousTokenRequest.TraceContextEntry
+	(*timestamppb.Timestamp)(nil),       // 21: google.protobuf.Timestamp
 }
 var file_barpilot_token_renewer_v1_token_proto_depIdxs = []int32{
-	4, // 0: barpilot.token_renewer.v1.RenewTokenResponse.expiration:type_name -> google.protobuf.Timestamp
-	4, // 1: barpilot.token_renewer.v1.GetTokenValidityResponse.expiration:type_name -> google.protobuf.Timestamp
-	0, // 2: barpilot.token_renewer.v1.TokenProviderService.RenewToken:input_type -> barpilot.token_renewer.v1.RenewTokenRequest
-	2, // 3: barpilot.token_renewer.v1.TokenProviderService.GetTokenValidity:input_type -> barpilot.token_renewer.v1.GetTokenValidityRequest
-	1, // 4: barpilot.token_renewer.v1.TokenProviderService.RenewToken:output_type -> barpilot.token_renewer.v1.RenewTokenResponse
-	3, // 5: barpilot.token_renewer.v1.TokenProviderService.GetTokenValidity:output_type -> barpilot.token_renewer.v1.GetTokenValidityResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	8,  // 0: barpilot.token_renewer.v1.RenewTokenRequest.metadata_fields:type_name -> barpilot.token_renewer.v1.RenewTokenRequest.MetadataFieldsEntry
+	9,  // 1: barpilot.token_renewer.v1.RenewTokenRequest.credentials:type_name -> barpilot.token_renewer.v1.RenewTokenRequest.CredentialsEntry
+	10, // 2: barpilot.token_renewer.v1.RenewTokenRequest.trace_context:type_name -> barpilot.token_renewer.v1.RenewTokenRequest.TraceContextEntry
+	21, // 3: barpilot.token_renewer.v1.RenewTokenResponse.expiration:type_name -> google.protobuf.Timestamp
+	11, // 4: barpilot.token_renewer.v1.RenewTokenResponse.new_metadata_fields:type_name -> barpilot.token_renewer.v1.RenewTokenResponse.NewMetadataFieldsEntry
+	12, // 5: barpilot.token_renewer.v1.GetTokenValidityRequest.metadata_fields:type_name -> barpilot.token_renewer.v1.GetTokenValidityRequest.MetadataFieldsEntry
+	13, // 6: barpilot.token_renewer.v1.GetTokenValidityRequest.credentials:type_name -> barpilot.token_renewer.v1.GetTokenValidityRequest.CredentialsEntry
+	14, // 7: barpilot.token_renewer.v1.GetTokenValidityRequest.trace_context:type_name -> barpilot.token_renewer.v1.GetTokenValidityRequest.TraceContextEntry
+	21, // 8: barpilot.token_renewer.v1.GetTokenValidityResponse.expiration:type_name -> google.protobuf.Timestamp
+	15, // 9: barpilot.token_renewer.v1.RevokeTokenRequest.metadata_fields:type_name -> barpilot.token_renewer.v1.RevokeTokenRequest.MetadataFieldsEntry
+	16, // 10: barpilot.token_renewer.v1.RevokeTokenRequest.credentials:type_name -> barpilot.token_renewer.v1.RevokeTokenRequest.CredentialsEntry
+	17, // 11: barpilot.token_renewer.v1.RevokeTokenRequest.trace_context:type_name -> barpilot.token_renewer.v1.RevokeTokenRequest.TraceContextEntry
+	18, // 12: barpilot.token_renewer.v1.RevokePreviousTokenRequest.metadata_fields:type_name -> barpilot.token_renewer.v1.RevokePreviousTokenRequest.MetadataFieldsEntry
+	19, // 13: barpilot.token_renewer.v1.RevokePreviousTokenRequest.credentials:type_name -> barpilot.token_renewer.v1.RevokePreviousTokenRequest.CredentialsEntry
+	20, // 14: barpilot.token_renewer.v1.RevokePreviousTokenRequest.trace_context:type_name -> barpilot.token_renewer.v1.RevokePreviousTokenRequest.TraceContextEntry
+	0,  // 15: barpilot.token_renewer.v1.TokenProviderService.RenewToken:input_type -> barpilot.token_renewer.v1.RenewTokenRequest
+	2,  // 16: barpilot.token_renewer.v1.TokenProviderService.GetTokenValidity:input_type -> barpilot.token_renewer.v1.GetTokenValidityRequest
+	4,  // 17: barpilot.token_renewer.v1.TokenProviderService.RevokeToken:input_type -> barpilot.token_renewer.v1.RevokeTokenRequest
+	6,  // 18: barpilot.token_renewer.v1.TokenProviderService.RevokePreviousToken:input_type -> barpilot.token_renewer.v1.RevokePreviousTokenRequest
+	1,  // 19: barpilot.token_renewer.v1.TokenProviderService.RenewToken:output_type -> barpilot.token_renewer.v1.RenewTokenResponse
+	3,  // 20: barpilot.token_renewer.v1.TokenProviderService.GetTokenValidity:output_type -> barpilot.token_renewer.v1.GetTokenValidityResponse
+	5,  // 21: barpilot.token_renewer.v1.TokenProviderService.RevokeToken:output_type -> barpilot.token_renewer.v1.RevokeTokenResponse
+	7,  // 22: barpilot.token_renewer.v1.TokenProviderService.RevokePreviousToken:output_type -> barpilot.token_renewer.v1.RevokePreviousTokenResponse
+	19, // [19:23] is the sub-list for method output_type
+	15, // [15:19] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_barpilot_token_renewer_v1_token_proto_init() }
@@ -306,7 +749,7 @@ func file_barpilot_token_renewer_v1_token_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_barpilot_token_renewer_v1_token_proto_rawDesc), len(file_barpilot_token_renewer_v1_token_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,8 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TokenProviderService_RenewToken_FullMethodName       = "/barpilot.token_renewer.v1.TokenProviderService/RenewToken"
-	TokenProviderService_GetTokenValidity_FullMethodName = "/barpilot.token_renewer.v1.TokenProviderService/GetTokenValidity"
+	TokenProviderService_RenewToken_FullMethodName          = "/barpilot.token_renewer.v1.TokenProviderService/RenewToken"
+	TokenProviderService_GetTokenValidity_FullMethodName    = "/barpilot.token_renewer.v1.TokenProviderService/GetTokenValidity"
+	TokenProviderService_RevokeToken_FullMethodName         = "/barpilot.token_renewer.v1.TokenProviderService/RevokeToken"
+	TokenProviderService_RevokePreviousToken_FullMethodName = "/barpilot.token_renewer.v1.TokenProviderService/RevokePreviousToken"
 )
 
 // TokenProviderServiceClient is the client API for TokenProviderService service.
@@ -33,6 +35,12 @@ type TokenProviderServiceClient interface {
 	RenewToken(ctx context.Context, in *RenewTokenRequest, opts ...grpc.CallOption) (*RenewTokenResponse, error)
 	// GetTokenValidity checks the validity of a token and returns its expiration time.
 	GetTokenValidity(ctx context.Context, in *GetTokenValidityRequest, opts ...grpc.CallOption) (*GetTokenValidityResponse, error)
+	// RevokeToken revokes a token on the provider side. Revoking a token that
+	// no longer exists succeeds.
+	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error)
+	// RevokePreviousToken revokes the previous token a two-phase RenewToken kept
+	// valid. It is only called on plugins that reported previous_token_retained.
+	RevokePreviousToken(ctx context.Context, in *RevokePreviousTokenRequest, opts ...grpc.CallOption) (*RevokePreviousTokenResponse, error)
 }
 
 type tokenProviderServiceClient struct {
@@ -63,6 +71,26 @@ func (c *tokenProviderServiceClient) GetTokenValidity(ctx context.Context, in *G
 	return out, nil
 }
 
+func (c *tokenProviderServiceClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeTokenResponse)
+	err := c.cc.Invoke(ctx, TokenProviderService_RevokeToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tokenProviderServiceClient) RevokePreviousToken(ctx context.Context, in *RevokePreviousTokenRequest, opts ...grpc.CallOption) (*RevokePreviousTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokePreviousTokenResponse)
+	err := c.cc.Invoke(ctx, TokenProviderService_RevokePreviousToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TokenProviderServiceServer is the server API for TokenProviderService service.
 // All implementations must embed UnimplementedTokenProviderServiceServer
 // for forward compatibility.
@@ -73,6 +101,12 @@ type TokenProviderServiceServer interface {
 	RenewToken(context.Context, *RenewTokenRequest) (*RenewTokenResponse, error)
 	// GetTokenValidity checks the validity of a token and returns its expiration time.
 	GetTokenValidity(context.Context, *GetTokenValidityRequest) (*GetTokenValidityResponse, error)
+	// RevokeToken revokes a token on the provider side. Revoking a token that
+	// no longer exists succeeds.
+	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error)
+	// RevokePreviousToken revokes the previous token a two-phase RenewToken kept
+	// valid. It is only called on plugins that reported previous_token_retained.
+	RevokePreviousToken(context.Context, *RevokePreviousTokenRequest) (*RevokePreviousTokenResponse, error)
 	mustEmbedUnimplementedTokenProviderServiceServer()
 }
 
@@ -89,6 +123,12 @@ func (UnimplementedTokenProviderServiceServer) RenewToken(context.Context, *Rene
 func (UnimplementedTokenProviderServiceServer) GetTokenValidity(context.Context, *GetTokenValidityRequest) (*GetTokenValidityResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTokenValidity not implemented")
 }
+func (UnimplementedTokenProviderServiceServer) RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeToken not implemented")
+}
+func (UnimplementedTokenProviderServiceServer) RevokePreviousToken(context.Context, *RevokePreviousTokenRequest) (*RevokePreviousTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokePreviousToken not implemented")
+}
 func (UnimplementedTokenProviderServiceServer) mustEmbedUnimplementedTokenProviderServiceServer() {}
 func (UnimplementedTokenProviderServiceServer) testEmbeddedByValue()                              {}
 
@@ -146,6 +186,42 @@ func _TokenProviderService_GetTokenValidity_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TokenProviderService_RevokeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenProviderServiceServer).RevokeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenProviderService_RevokeToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenProviderServiceServer).RevokeToken(ctx, req.(*RevokeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TokenProviderService_RevokePreviousToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokePreviousTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenProviderServiceServer).RevokePreviousToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenProviderService_RevokePreviousToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenProviderServiceServer).RevokePreviousToken(ctx, req.(*RevokePreviousTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TokenProviderService_ServiceDesc is the grpc.ServiceDesc for TokenProviderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -161,6 +237,14 @@ var TokenProviderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTokenValidity",
 			Handler:    _TokenProviderService_GetTokenValidity_Handler,
 		},
+		{
+			MethodName: "RevokeToken",
+			Handler:    _TokenProviderService_RevokeToken_Handler,
+		},
+		{
+			MethodName: "RevokePreviousToken",
+			Handler:    _TokenProviderService_RevokePreviousToken_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "barpilot/token_renewer/v1/token.proto",
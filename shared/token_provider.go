@@ -2,14 +2,71 @@ package shared
 
 import (
 	context "context"
+	"errors"
 	"time"
 )
 
+// ErrTokenInvalid is wrapped by the errors of GetTokenValidity when the
+// provider reports the token revoked or unknown, as opposed to failing to
+// answer. Plugins report it with the gRPC code NotFound or Unauthenticated.
+var ErrTokenInvalid = errors.New("token is no longer valid")
+
+// Metadata identifies a token on the provider side.
+type Metadata struct {
+	// Value is the legacy opaque metadata string.
+	Value string
+	// Fields is the structured metadata, for example an account ID, a region and a label.
+	Fields map[string]string
+}
+
+// Credentials are the values of the credentials Secret of a Token. Providers
+// whose tokens cannot renew themselves authenticate with them instead of the
+// token. Credentials are nil when the Token has no credentials Secret.
+type Credentials map[string]string
+
+// RenewRequest is a token renewal, as sent in a RenewTokenRequest.
+type RenewRequest struct {
+	// Metadata identifies the token to renew.
+	Metadata Metadata
+	// Token is the current token.
+	Token string
+	// Credentials of the Token, nil when it has no credentials Secret.
+	Credentials Credentials
+	// RetainPrevious asks providers supporting two-phase rotation to keep the
+	// previous token valid until RevokePreviousToken.
+	RetainPrevious bool
+	// IdempotencyKey is the same for every retry of a rotation; providers
+	// return the token they already minted for a key instead of minting
+	// another one, as long as they still remember the key.
+	IdempotencyKey string
+}
+
+// RenewResult is a renewed token, as returned in a RenewTokenResponse.
+type RenewResult struct {
+	// Token is the new token.
+	Token string
+	// Metadata identifies the new token.
+	Metadata Metadata
+	// Expiration is the expiration time of the new token.
+	Expiration *time.Time
+	// PreviousRetained reports that the previous token is still valid.
+	PreviousRetained bool
+}
+
 // TokenProvider defines the interface for token management.
 type TokenProvider interface {
 	// RenewToken renews a token and returns the new token, metadata, and expiration time.
-	RenewToken(ctx context.Context, metadata, token string) (newToken string, newMetadata string, expiration *time.Time, err error)
+	RenewToken(ctx context.Context, req RenewRequest) (RenewResult, error)
 
 	// GetTokenValidity checks the validity of a token and returns its expiration time.
-	GetTokenValidity(ctx context.Context, metadata, token string) (expiration *time.Time, err error)
+	// The error wraps ErrTokenInvalid when the provider reports the token invalid.
+	GetTokenValidity(ctx context.Context, metadata Metadata, token string, credentials Credentials) (expiration *time.Time, err error)
+
+	// RevokeToken revokes a token on the provider side. Revoking a token that
+	// no longer exists succeeds.
+	RevokeToken(ctx context.Context, metadata Metadata, token string, credentials Credentials) error
+
+	// RevokePreviousToken revokes the previous token, identified by its
+	// metadata, that a two-phase rotation kept valid. token is the current token.
+	RevokePreviousToken(ctx context.Context, previous Metadata, token string, credentials Credentials) error
 }
@@ -0,0 +1,45 @@
+package shared
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// InjectTraceContext returns the trace context of ctx to send in the
+// trace_context field of a request, or nil when ctx carries no trace.
+func InjectTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// ExtractTraceContext returns ctx continuing the trace received in the
+// trace_context field of a request.
+func ExtractTraceContext(ctx context.Context, traceContext map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(traceContext))
+}
+
+// SetTraceContext sets the trace_context field of the request.
+func (x *RenewTokenRequest) SetTraceContext(traceContext map[string]string) {
+	x.TraceContext = traceContext
+}
+
+// SetTraceContext sets the trace_context field of the request.
+func (x *GetTokenValidityRequest) SetTraceContext(traceContext map[string]string) {
+	x.TraceContext = traceContext
+}
+
+// SetTraceContext sets the trace_context field of the request.
+func (x *RevokeTokenRequest) SetTraceContext(traceContext map[string]string) {
+	x.TraceContext = traceContext
+}
+
+// SetTraceContext sets the trace_context field of the request.
+func (x *RevokePreviousTokenRequest) SetTraceContext(traceContext map[string]string) {
+	x.TraceContext = traceContext
+}